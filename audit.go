@@ -0,0 +1,111 @@
+package ladon
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditLogger records every decision made by the warden.
+type AuditLogger interface {
+	// LogDecision records the decision for request r, whose Time is the time it was evaluated at. policies are
+	// the candidates returned by the manager, policy is the policy which decided the request (nil if none
+	// matched) and err is the outcome (nil if access was granted). If an error is returned, the request is
+	// denied.
+	LogDecision(r *Request, policies Policies, policy Policy, err error) error
+}
+
+// AuditEntry is the record of a single warden decision.
+type AuditEntry struct {
+	// Time is the time the request was evaluated at.
+	Time time.Time `json:"time"`
+
+	// Resource is the requested resource.
+	Resource string `json:"resource"`
+
+	// Action is the requested action.
+	Action string `json:"action"`
+
+	// Subjects are the requesting subjects.
+	Subjects []string `json:"subjects"`
+
+	// Context is the request's context without the raw HTTP request. Values which can not be encoded as
+	// json are stored in their string form.
+	Context Context `json:"context,omitempty"`
+
+	// Candidates are the IDs of the policies that were checked.
+	Candidates []string `json:"candidates"`
+
+	// Policy is the ID of the policy which decided the request.
+	Policy string `json:"policy,omitempty"`
+
+	// Effect is the effect of the policy which decided the request.
+	Effect string `json:"effect,omitempty"`
+
+	// Allowed is true if access was granted.
+	Allowed bool `json:"allowed"`
+
+	// Error is the reason access was denied.
+	Error string `json:"error,omitempty"`
+
+	// PrevHash is the hash of the previous entry in the log.
+	PrevHash string `json:"prev_hash,omitempty"`
+
+	// Hash is the hash of this entry including PrevHash.
+	Hash string `json:"hash,omitempty"`
+}
+
+// NewAuditEntry creates an audit entry describing a warden decision. Its time is the request's time or, if
+// the request has none, the current time.
+func NewAuditEntry(r *Request, policies Policies, policy Policy, err error) *AuditEntry {
+	e := &AuditEntry{
+		Time:       time.Now().UTC(),
+		Candidates: make([]string, len(policies)),
+		Allowed:    err == nil,
+	}
+
+	if r != nil {
+		if !r.Time.IsZero() {
+			e.Time = r.Time.UTC()
+		}
+		e.Resource = r.Resource
+		e.Action = r.Action
+		e.Subjects = r.Subjects
+		for k, v := range r.Context {
+			// The raw request can not be serialized and is not of interest for the audit trail.
+			if k == KeyRawRequest {
+				continue
+			}
+			if e.Context == nil {
+				e.Context = Context{}
+			}
+			e.Context[k] = auditValue(v)
+		}
+	}
+
+	for i, p := range policies {
+		e.Candidates[i] = p.GetID()
+	}
+
+	if policy != nil {
+		e.Policy = policy.GetID()
+		e.Effect = policy.GetEffect()
+	}
+
+	if err != nil {
+		e.Error = errors.Cause(err).Error()
+	}
+
+	return e
+}
+
+// auditValue returns the value if it can be encoded as json and its string form otherwise, so that a value
+// the caller put in the context does not prevent the decision from being recorded.
+func auditValue(v interface{}) interface{} {
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return v
+}
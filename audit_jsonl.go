@@ -0,0 +1,150 @@
+package ladon
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// JSONLinesAuditLogger writes one JSON encoded AuditEntry per line. Every entry carries the hash of its
+// predecessor so that removed, reordered or modified entries can be detected with VerifyAuditLog.
+type JSONLinesAuditLogger struct {
+	w    io.Writer
+	prev string
+	sync.Mutex
+}
+
+// NewJSONLinesAuditLogger creates an audit logger writing to w.
+func NewJSONLinesAuditLogger(w io.Writer) *JSONLinesAuditLogger {
+	return &JSONLinesAuditLogger{w: w}
+}
+
+// NewFileAuditLogger opens or creates the audit log at path and appends to it. The existing log is
+// verified first so that the hash chain can be continued.
+func NewFileAuditLogger(path string) (*JSONLinesAuditLogger, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	prev, err := verifyAuditLog(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &JSONLinesAuditLogger{w: f, prev: prev}, nil
+}
+
+// LogDecision writes the decision to the log.
+func (l *JSONLinesAuditLogger) LogDecision(r *Request, policies Policies, policy Policy, err error) error {
+	e := NewAuditEntry(r, policies, policy, err)
+
+	l.Lock()
+	defer l.Unlock()
+
+	e.PrevHash = l.prev
+	hash, herr := hashAuditEntry(e)
+	if herr != nil {
+		return herr
+	}
+	e.Hash = hash
+
+	line, merr := json.Marshal(e)
+	if merr != nil {
+		return errors.WithStack(merr)
+	}
+	if _, werr := l.w.Write(append(line, '\n')); werr != nil {
+		return errors.WithStack(werr)
+	}
+
+	l.prev = hash
+	return nil
+}
+
+// Close closes the underlying writer if it is closable.
+func (l *JSONLinesAuditLogger) Close() error {
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// VerifyAuditLog reads a log written by JSONLinesAuditLogger and returns an error if the hash chain is broken.
+func VerifyAuditLog(r io.Reader) error {
+	_, err := verifyAuditLog(r)
+	return err
+}
+
+func verifyAuditLog(r io.Reader) (string, error) {
+	var prev string
+	var line int
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		line++
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+
+		var e AuditEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return "", errors.Wrapf(err, "audit log line %d", line)
+		}
+		if e.PrevHash != prev {
+			return "", errors.Errorf("audit log line %d does not follow its predecessor", line)
+		}
+
+		hash, err := canonicalAuditHash(s.Bytes(), prev)
+		if err != nil {
+			return "", errors.Wrapf(err, "audit log line %d", line)
+		}
+		if hash != e.Hash {
+			return "", errors.Errorf("audit log line %d has been modified", line)
+		}
+		prev = e.Hash
+	}
+
+	if err := s.Err(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return prev, nil
+}
+
+func hashAuditEntry(e *AuditEntry) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return canonicalAuditHash(data, e.PrevHash)
+}
+
+// canonicalAuditHash hashes the entry in a form that survives decoding and encoding, i.e. with sorted
+// keys, unchanged numbers and without the hash itself.
+func canonicalAuditHash(data []byte, prev string) (string, error) {
+	var v map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return "", errors.WithStack(err)
+	}
+	delete(v, "hash")
+
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
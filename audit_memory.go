@@ -0,0 +1,42 @@
+package ladon
+
+import (
+	"sync"
+)
+
+// MemoryAuditLogger keeps all audit entries in memory. It is useful for testing.
+type MemoryAuditLogger struct {
+	entries []*AuditEntry
+	sync.RWMutex
+}
+
+// NewMemoryAuditLogger creates an empty MemoryAuditLogger.
+func NewMemoryAuditLogger() *MemoryAuditLogger {
+	return &MemoryAuditLogger{entries: []*AuditEntry{}}
+}
+
+// LogDecision stores the decision.
+func (l *MemoryAuditLogger) LogDecision(r *Request, policies Policies, policy Policy, err error) error {
+	e := NewAuditEntry(r, policies, policy, err)
+
+	l.Lock()
+	defer l.Unlock()
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+// Entries returns all recorded entries in the order they were logged.
+func (l *MemoryAuditLogger) Entries() []*AuditEntry {
+	l.RLock()
+	defer l.RUnlock()
+	es := make([]*AuditEntry, len(l.entries))
+	copy(es, l.entries)
+	return es
+}
+
+// Reset removes all recorded entries.
+func (l *MemoryAuditLogger) Reset() {
+	l.Lock()
+	defer l.Unlock()
+	l.entries = []*AuditEntry{}
+}
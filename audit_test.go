@@ -0,0 +1,108 @@
+package ladon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogger(t *testing.T) {
+	audit := NewMemoryAuditLogger()
+	warden := &Ladon{Manager: NewMemoryManager(), AuditLogger: audit}
+	for _, pol := range pols {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expectErr, warden.IsAllowed(c.accessRequest) != nil)
+	}
+
+	entries := audit.Entries()
+	require.Len(t, entries, len(cases))
+	for k, c := range cases {
+		assert.Equal(t, !c.expectErr, entries[k].Allowed, c.description)
+	}
+
+	// max may update everything because of policy 2
+	assert.Equal(t, "2", entries[3].Policy)
+	assert.Equal(t, AllowAccess, entries[3].Effect)
+	// max may not broadcast because of policy 3
+	assert.Equal(t, "3", entries[5].Policy)
+	assert.Equal(t, DenyAccess, entries[5].Effect)
+	// no policy matches
+	assert.Equal(t, "", entries[0].Policy)
+	assert.NotEmpty(t, entries[0].Error)
+}
+
+func TestJSONLinesAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	warden := &Ladon{Manager: NewMemoryManager(), AuditLogger: NewJSONLinesAuditLogger(&buf)}
+	for _, pol := range pols {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+	for _, c := range cases {
+		warden.IsAllowed(c.accessRequest)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(cases))
+	require.NoError(t, VerifyAuditLog(strings.NewReader(buf.String())))
+
+	// dropping an entry breaks the chain
+	tampered := strings.Join(append(lines[:1], lines[2:]...), "\n")
+	assert.Error(t, VerifyAuditLog(strings.NewReader(tampered)))
+
+	// modifying an entry breaks its hash
+	tampered = strings.Replace(buf.String(), `"allowed":false`, `"allowed":true`, 1)
+	assert.Error(t, VerifyAuditLog(strings.NewReader(tampered)))
+}
+
+func TestJSONLinesAuditLoggerContextAndTime(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2017, 6, 5, 10, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	warden := &Ladon{
+		Manager:     NewMemoryManager(),
+		AuditLogger: NewJSONLinesAuditLogger(&buf),
+		Clock:       func() time.Time { return now },
+	}
+	require.Nil(t, warden.Manager.Create(&DefaultPolicy{
+		ID:        "1",
+		Subjects:  []string{"peter"},
+		Resources: []string{"articles:1"},
+		Actions:   []string{"view"},
+		Effect:    AllowAccess,
+	}))
+
+	// Values which can not be encoded must not deny the request.
+	require.NoError(t, warden.IsAllowed(&Request{
+		Subjects: []string{"peter"},
+		Resource: "articles:1",
+		Action:   "view",
+		Context:  Context{"callback": make(chan int), "owner": "peter"},
+	}))
+	errs := warden.IsAllowedBatch([]*Request{{
+		Subjects: []string{"peter"},
+		Resource: "articles:1",
+		Action:   "view",
+		Context:  Context{"callback": func() {}},
+	}})
+	require.NoError(t, errs[0])
+	require.NoError(t, VerifyAuditLog(strings.NewReader(buf.String())))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var e AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		assert.True(t, e.Allowed)
+		assert.True(t, now.Equal(e.Time), "%s", e.Time)
+		assert.IsType(t, "", e.Context["callback"])
+	}
+}
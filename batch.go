@@ -0,0 +1,130 @@
+package ladon
+
+import (
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IsAllowedBatch decides a list of requests and returns one error per request, nil meaning access is granted.
+// If the manager implements BatchManager, candidates are fetched only once per distinct set of subjects.
+// Requests are evaluated concurrently, but never more than BatchConcurrency at a time. Requests sharing the
+// same raw http request share its decoded body, so the body is read only once.
+func (l *Ladon) IsAllowedBatch(rs []*Request) []error {
+	errs := make([]error, len(rs))
+	if len(rs) == 0 {
+		return errs
+	}
+
+	// The matcher is set lazily, so make sure this happens before any concurrent access.
+	l.matcher()
+	rs = l.shareBodies(rs)
+
+	bm, ok := l.Manager.(BatchManager)
+	if !ok {
+		l.parallel(len(rs), func(i int) {
+			errs[i] = l.IsAllowed(rs[i])
+		})
+		return errs
+	}
+
+	// Group the requests by their subjects so that every subject set is only looked up once.
+	var keys []string
+	groups := map[string][]int{}
+	for i, r := range rs {
+		key := subjectsKey(r.Subjects)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	candidates := make([]Policies, len(keys))
+	lookupErrs := make([]error, len(keys))
+	l.parallel(len(keys), func(k int) {
+		candidates[k], lookupErrs[k] = bm.FindSubjectCandidates(rs[groups[keys[k]][0]].Subjects)
+		if lookupErrs[k] != nil {
+			l.logger().Error("could not find subject candidates", "error", lookupErrs[k])
+		}
+	})
+
+	policies := make([]Policies, len(rs))
+	for k, key := range keys {
+		for _, i := range groups[key] {
+			if lookupErrs[k] != nil {
+				errs[i] = l.audit(rs[i], nil, nil, lookupErrs[k])
+				continue
+			}
+			policies[i] = candidates[k]
+		}
+	}
+
+	l.parallel(len(rs), func(i int) {
+		if errs[i] != nil {
+			return
+		}
+		errs[i] = l.isAllowed(rs[i], policies[i])
+	})
+	return errs
+}
+
+// parallel calls fn for 0 <= i < n using at most BatchConcurrency goroutines.
+func (l *Ladon) parallel(n int, fn func(i int)) {
+	workers := l.BatchConcurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// shareBodies returns prepared copies of the requests in which requests with the same raw http request share
+// one decoded body. Otherwise the workers would read and restore the body of the raw http request concurrently.
+func (l *Ladon) shareBodies(rs []*Request) []*Request {
+	bodies := map[*http.Request]*requestBody{}
+	shared := make([]*Request, len(rs))
+	for i, r := range rs {
+		req, ok := rawRequest(r)
+		if !ok {
+			shared[i] = l.prepare(r)
+			continue
+		}
+		b, ok := bodies[req]
+		if !ok {
+			b = newRequestBody(l.MaxBodySize)
+			bodies[req] = b
+		}
+		sr := *r
+		sr.body = b
+		shared[i] = l.prepare(&sr)
+	}
+	return shared
+}
+
+func subjectsKey(subjects []string) string {
+	s := make([]string, len(subjects))
+	copy(s, subjects)
+	sort.Strings(s)
+	return strings.Join(s, "\x00")
+}
@@ -0,0 +1,87 @@
+package ladon_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainManager hides the BatchManager implementation of the wrapped manager.
+type plainManager struct {
+	Manager
+}
+
+func TestLadonIsAllowedBatch(t *testing.T) {
+	m := NewMemoryManager()
+	for _, pol := range pols {
+		require.Nil(t, m.Create(pol))
+	}
+
+	var requests []*Request
+	for _, c := range cases {
+		requests = append(requests, c.accessRequest)
+	}
+
+	for k, warden := range []*Ladon{
+		{Manager: m},
+		{Manager: m, BatchConcurrency: 1},
+		{Manager: &plainManager{m}, BatchConcurrency: 2},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			errs := warden.IsAllowedBatch(requests)
+			require.Len(t, errs, len(cases))
+			for i, c := range cases {
+				assert.Equal(t, c.expectErr, errs[i] != nil, c.description)
+			}
+		})
+	}
+
+	assert.Len(t, (&Ladon{Manager: m}).IsAllowedBatch(nil), 0)
+}
+
+func TestLadonIsAllowedBatchSharedRawRequest(t *testing.T) {
+	doc := `{"owner":"peter","rows":["a","b","c"]}`
+	req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/rows", strings.NewReader(doc))
+	req.Header.Set("Content-type", "application/json")
+
+	m := NewMemoryManager()
+	require.NoError(t, m.Create(&DefaultPolicy{
+		ID:         "rows",
+		Subjects:   []string{"peter"},
+		Actions:    []string{"get"},
+		Resources:  []string{"rows:<.+>"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"owner": &BodyMatchCondition{Path: ".owner", Matches: "peter"}},
+	}))
+
+	// One http call checks many rows, so all requests share the raw http request.
+	var requests []*Request
+	for i := 0; i < 64; i++ {
+		requests = append(requests, &Request{
+			Subjects: []string{"peter"},
+			Action:   "get",
+			Resource: fmt.Sprintf("rows:%d", i),
+			Context:  Context{KeyRawRequest: req},
+		})
+	}
+
+	for k, warden := range []*Ladon{
+		{Manager: m, BatchConcurrency: 8},
+		{Manager: &plainManager{m}, BatchConcurrency: 8},
+	} {
+		for i, err := range warden.IsAllowedBatch(requests) {
+			assert.NoError(t, err, "case %d request %d", k, i)
+		}
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, doc, string(b))
+}
@@ -0,0 +1,315 @@
+package ladon
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMaxBodySize is the maximum number of bytes of a request body which is read if Ladon.MaxBodySize is
+// not set.
+const DefaultMaxBodySize int64 = 10 << 20
+
+// requestBody lazily reads and decodes the body of the raw http request once, so that all conditions of an
+// authorization call share the same document.
+type requestBody struct {
+	once sync.Once
+	max  int64
+
+	doc interface{}
+	ok  bool
+	err error
+}
+
+func newRequestBody(max int64) *requestBody {
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	return &requestBody{max: max}
+}
+
+// document returns the decoded body of the raw http request in the request's context. It returns false if
+// there is no body and an error if the body can not be read, is larger than the maximum size, has no or an
+// unsupported media type or can not be decoded. Conditions must not treat such a body as missing, otherwise
+// e.g. a json body sent as text/plain would pass checks for the absence of a field.
+func (b *requestBody) document(r *Request) (interface{}, bool, error) {
+	b.once.Do(func() {
+		req, ok := rawRequest(r)
+		if !ok || req.Body == nil {
+			return
+		}
+
+		var body []byte
+		if body, b.err = readBody(req, b.max); b.err != nil || len(bytes.TrimSpace(body)) == 0 {
+			return
+		}
+		mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-type"))
+		if err != nil {
+			b.err = errors.Wrap(err, "could not parse the content type of the request body")
+			return
+		}
+		decode := bodyDecoder(mediaType)
+		if decode == nil {
+			b.err = errors.Errorf("request body has the unsupported content type %q", mediaType)
+			return
+		}
+		doc, err := decode(body, params)
+		if err != nil {
+			b.err = errors.Wrap(err, "could not decode request body")
+			return
+		}
+		if doc != nil {
+			b.doc, b.ok = doc, true
+		}
+	})
+	return b.doc, b.ok, b.err
+}
+
+// readBody reads at most max bytes of the body of the http request. The body is restored, so it can be read
+// again.
+func readBody(req *http.Request, max int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, max+1))
+	req.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read request body")
+	}
+	if int64(len(body)) > max {
+		return nil, errors.Errorf("request body exceeds the maximum size of %d bytes", max)
+	}
+	return body, nil
+}
+
+// readCloser restores a partially read body while keeping the original closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyDecoder returns the function which decodes bodies of the media type or nil if the media type is not
+// supported.
+func bodyDecoder(mediaType string) func(body []byte, params map[string]string) (interface{}, error) {
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return decodeXML
+	case mediaType == "application/yaml" || mediaType == "application/x-yaml" || mediaType == "text/yaml" ||
+		mediaType == "text/x-yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return decodeYAML
+	case mediaType == "application/x-www-form-urlencoded":
+		return decodeForm
+	case mediaType == "multipart/form-data":
+		return decodeMultipart
+	default:
+		return nil
+	}
+}
+
+func decodeJSON(body []byte, _ map[string]string) (interface{}, error) {
+	var doc interface{}
+	err := json.Unmarshal(body, &doc)
+	return doc, errors.WithStack(err)
+}
+
+// decodeYAML decodes the first document of a yaml body. Keys are converted to strings, numbers to float64 and
+// timestamps are formatted as RFC 3339, so the document can be queried like a json document.
+func decodeYAML(body []byte, _ map[string]string) (interface{}, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return normalizeYAML(doc), nil
+}
+
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeYAML(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeYAML(e)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// decodeXML decodes an xml body to an object holding the root element. An element becomes a string of its
+// text if it has neither attributes nor child elements and an object otherwise. Attributes are stored with
+// their name prefixed by @, child elements by their name, as an array if the name repeats, and the text as
+// #text. Namespaces are dropped.
+func decodeXML(body []byte, _ map[string]string) (interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			root, err := decodeXMLElement(d, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	obj := map[string]interface{}{}
+	for _, a := range start.Attr {
+		obj["@"+a.Name.Local] = a.Value
+	}
+
+	var text strings.Builder
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			addValue(obj, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				obj["#text"] = s
+			}
+			return obj, nil
+		}
+	}
+}
+
+// decodeForm decodes an url encoded form to an object. Fields are strings, or arrays of strings if they
+// have more than one value.
+func decodeForm(body []byte, _ map[string]string) (interface{}, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	obj := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		for _, v := range vs {
+			addValue(obj, k, v)
+		}
+	}
+	return obj, nil
+}
+
+// decodeMultipart decodes a multipart form to an object like decodeForm. Files are objects holding their
+// filename, content_type and size.
+func decodeMultipart(body []byte, params map[string]string) (interface{}, error) {
+	boundary, ok := params["boundary"]
+	if !ok {
+		return nil, errors.New("multipart body without boundary")
+	}
+
+	obj := map[string]interface{}{}
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return obj, nil
+		} else if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if part.FormName() == "" {
+			continue
+		}
+
+		if part.FileName() != "" {
+			size, err := io.Copy(ioutil.Discard, part)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			addValue(obj, part.FormName(), map[string]interface{}{
+				"filename":     part.FileName(),
+				"content_type": part.Header.Get("Content-Type"),
+				"size":         float64(size),
+			})
+			continue
+		}
+
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		addValue(obj, part.FormName(), string(value))
+	}
+}
+
+// addValue stores the value under the key or, if the key is already set, appends it to an array of the
+// values.
+func addValue(obj map[string]interface{}, key string, value interface{}) {
+	existing, ok := obj[key]
+	if !ok {
+		obj[key] = value
+		return
+	}
+	if values, ok := existing.([]interface{}); ok {
+		obj[key] = append(values, value)
+		return
+	}
+	obj[key] = []interface{}{existing, value}
+}
+
+// bodyValue returns the value at the path (see Query) in the body of the raw http request. It returns false
+// if there is no body of a supported media type or no value at the path and an error if the path is invalid
+// or the body can not be read. The body is decoded only once per authorization call; outside of one, it is
+// decoded on every call.
+func bodyValue(r *Request, path string) (interface{}, bool, error) {
+	q, err := cachedQuery(path)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	b := r.body
+	if b == nil {
+		b = newRequestBody(0)
+	}
+	doc, ok, err := b.document(r)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	v, ok := q.Get(doc)
+	return v, ok, nil
+}
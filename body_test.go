@@ -0,0 +1,166 @@
+package ladon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingReader struct {
+	r     *strings.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestBodyDecodedOncePerCall(t *testing.T) {
+	doc := `{"owner":"peter","amount":50,"tags":["a","b"]}`
+	policy := func(conditions Conditions) Policy {
+		return &DefaultPolicy{
+			ID:         "orders",
+			Subjects:   []string{"peter"},
+			Actions:    []string{"create"},
+			Resources:  []string{"orders"},
+			Effect:     AllowAccess,
+			Conditions: conditions,
+		}
+	}
+	owner := &BodyMatchCondition{Path: ".owner", Matches: "peter"}
+	all := Conditions{
+		"owner":  owner,
+		"tags":   &BodyArrayMatchCondition{Mode: Matchall, Path: ".tags", Matches: "<[a-z]>"},
+		"amount": &NumericCompareCondition{Operator: "lt", Value: 100, Path: ".amount"},
+	}
+
+	// reads returns how often the body is read when the policies are evaluated.
+	reads := func(policies Policies) int {
+		body := &countingReader{r: strings.NewReader(doc)}
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", body)
+		req.Header.Set("Content-type", "application/json")
+		r := &Request{Subjects: []string{"peter"}, Action: "create", Resource: "orders", Context: Context{KeyRawRequest: req}}
+
+		_, err := (&Ladon{}).doPoliciesAllow(r, policies)
+		require.NoError(t, err)
+		n := body.reads
+
+		// The body can still be read by the handler.
+		b, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, doc, string(b))
+		return n
+	}
+
+	once := reads(Policies{policy(Conditions{"owner": owner})})
+	assert.True(t, once > 0)
+	assert.Equal(t, once, reads(Policies{policy(all), policy(all), policy(all)}))
+}
+
+func TestMaxBodySize(t *testing.T) {
+	doc := `{"owner":"peter"}`
+	newRequest := func() *Request {
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", bytes.NewBufferString(doc))
+		req.Header.Set("Content-type", "application/json")
+		return &Request{Subjects: []string{"peter"}, Action: "create", Resource: "orders", Context: Context{KeyRawRequest: req}}
+	}
+	p := &DefaultPolicy{
+		ID:         "orders",
+		Subjects:   []string{"peter"},
+		Actions:    []string{"create"},
+		Resources:  []string{"orders"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"owner": &BodyMatchCondition{Path: ".owner", Matches: "peter"}},
+	}
+
+	_, err := (&Ladon{MaxBodySize: int64(len(doc))}).doPoliciesAllow(newRequest(), Policies{p})
+	assert.NoError(t, err)
+
+	r := newRequest()
+	_, err = (&Ladon{MaxBodySize: int64(len(doc) - 1)}).doPoliciesAllow(r, Policies{p})
+	require.Error(t, err)
+	_, ok := errors.Cause(err).(*ConditionError)
+	assert.True(t, ok, "%s", err)
+
+	// The part of the body which was read is restored.
+	b, err := ioutil.ReadAll(r.Context[KeyRawRequest].(*http.Request).Body)
+	require.NoError(t, err)
+	assert.Equal(t, doc, string(b))
+}
+
+func TestBodyMediaTypes(t *testing.T) {
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	require.NoError(t, mw.WriteField("owner", "peter"))
+	require.NoError(t, mw.WriteField("tag", "a"))
+	require.NoError(t, mw.WriteField("tag", "b"))
+	fw, err := mw.CreateFormFile("upload", "report.pdf")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("%PDF-1.4"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	for k, c := range []struct {
+		contentType string
+		body        string
+		path        string
+		expected    interface{}
+		found       bool
+		err         bool
+	}{
+		{contentType: "application/json", body: `{"owner":"peter"}`, path: ".owner", expected: "peter", found: true},
+		{contentType: "application/json; charset=utf-8", body: `{"owner":"peter"}`, path: ".owner", expected: "peter", found: true},
+		{contentType: "Application/JSON", body: `{"owner":"peter"}`, path: ".owner", expected: "peter", found: true},
+		{contentType: "application/merge-patch+json", body: `{"owner":"peter"}`, path: ".owner", expected: "peter", found: true},
+		{contentType: "application/json", body: `{"owner":`, path: ".owner", err: true},
+		{contentType: "text/plain", body: `{"owner":"peter"}`, path: ".owner", err: true},
+		{contentType: "", body: `{"owner":"peter"}`, path: ".owner", err: true},
+		{contentType: "", body: "", path: ".owner"},
+		{contentType: "application/json", body: " ", path: ".owner"},
+		{contentType: "application/json", body: "null", path: ".owner"},
+
+		{contentType: "application/x-www-form-urlencoded", body: "owner=peter&tag=a&tag=b", path: ".owner", expected: "peter", found: true},
+		{contentType: "application/x-www-form-urlencoded", body: "owner=peter&tag=a&tag=b", path: ".tag[1]", expected: "b", found: true},
+		{contentType: "application/x-www-form-urlencoded; charset=utf-8", body: "owner=pe%20ter", path: ".owner", expected: "pe ter", found: true},
+
+		{contentType: mw.FormDataContentType(), body: multipartBody.String(), path: ".owner", expected: "peter", found: true},
+		{contentType: mw.FormDataContentType(), body: multipartBody.String(), path: ".tag", expected: []interface{}{"a", "b"}, found: true},
+		{contentType: mw.FormDataContentType(), body: multipartBody.String(), path: ".upload.filename", expected: "report.pdf", found: true},
+		{contentType: mw.FormDataContentType(), body: multipartBody.String(), path: ".upload.size", expected: float64(8), found: true},
+		{contentType: "multipart/form-data", body: multipartBody.String(), path: ".owner", err: true},
+
+		{contentType: "application/xml", body: `<order id="7"><owner>peter</owner><item>a</item><item>b</item></order>`, path: ".order.owner", expected: "peter", found: true},
+		{contentType: "text/xml; charset=utf-8", body: `<order id="7"><owner>peter</owner></order>`, path: `.order["@id"]`, expected: "7", found: true},
+		{contentType: "application/xml", body: `<order><item>a</item><item>b</item></order>`, path: ".order.item[1]", expected: "b", found: true},
+		{contentType: "application/xml", body: `<order><note lang="en">urgent</note></order>`, path: `.order.note["#text"]`, expected: "urgent", found: true},
+		{contentType: "application/atom+xml", body: `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>news</title></feed>`, path: ".feed.title", expected: "news", found: true},
+		{contentType: "application/xml", body: `<order><owner>peter</order>`, path: ".order.owner", err: true},
+
+		{contentType: "application/yaml", body: "owner: peter\namount: 50\ntags: [a, b]\n", path: ".owner", expected: "peter", found: true},
+		{contentType: "application/x-yaml", body: "owner: peter\namount: 50\n", path: ".amount", expected: float64(50), found: true},
+		{contentType: "text/yaml", body: "1: one\n", path: `.["1"]`, expected: "one", found: true},
+		{contentType: "application/yaml", body: "created: 2017-01-02T15:04:05Z\n", path: ".created", expected: "2017-01-02T15:04:05Z", found: true},
+		{contentType: "application/yaml", body: "owner: [peter\n", path: ".owner", err: true},
+		{contentType: "application/yaml", body: "items:\n- {id: a, price: 5}\n- {id: b, price: 20}\n", path: ".items[] | select(.price > 10) | .id", expected: []interface{}{"b"}, found: true},
+	} {
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", strings.NewReader(c.body))
+		req.Header.Set("Content-type", c.contentType)
+
+		v, found, err := bodyValue(&Request{Context: Context{KeyRawRequest: req}}, c.path)
+		if c.err {
+			assert.Error(t, err, "case %d", k)
+			continue
+		}
+		require.NoError(t, err, "case %d", k)
+		assert.Equal(t, c.found, found, "case %d", k)
+		assert.Equal(t, c.expected, v, "case %d", k)
+	}
+}
@@ -0,0 +1,98 @@
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GlobChars are the characters with a special meaning in glob patterns.
+const GlobChars = `*?{}\`
+
+// CompileGlob parses a glob pattern and returns a Regexp. A single asterisk matches any sequence of characters
+// except the separator, a double asterisk matches any sequence including the separator, a question mark matches
+// a single character except the separator and {a,b} matches one of the comma separated alternatives, which may
+// contain globs themselves. A backslash escapes the following character. Every wildcard is a capturing group
+// of the resulting Regexp.
+//
+// If separator is 0, * and ? also match any character.
+//
+//	reg, err := compiler.CompileGlob("resources:articles:*", ':')
+//	// if err != nil ...
+//	reg.MatchString("resources:articles:1234")
+func CompileGlob(glob string, separator byte) (*regexp.Regexp, error) {
+	pattern := bytes.NewBufferString("^")
+	if _, err := compileGlob(pattern, glob, separator, false); err != nil {
+		return nil, err
+	}
+	pattern.WriteByte('$')
+
+	return regexp.Compile(pattern.String())
+}
+
+// compileGlob writes the regular expression for glob to pattern. If nested is true, it stops at the end of
+// the current alternative, i.e. at an unescaped ',' or '}', and returns the remainder starting there. An
+// empty remainder means the alternative was never closed.
+func compileGlob(pattern *bytes.Buffer, glob string, separator byte, nested bool) (string, error) {
+	single := "(?s:.)"
+	if separator != 0 {
+		single = "[^" + regexp.QuoteMeta(string(separator)) + "]"
+	}
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '\\':
+			if i+1 == len(glob) {
+				return "", fmt.Errorf(`Trailing escape character in %q`, glob)
+			}
+			i++
+			pattern.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				pattern.WriteString("((?s:.*))")
+				continue
+			}
+			pattern.WriteString("(" + single + "*)")
+		case '?':
+			pattern.WriteString("(" + single + ")")
+		case '{':
+			pattern.WriteString("(?:")
+			rest := glob[i+1:]
+			for {
+				var err error
+				if rest, err = compileGlob(pattern, rest, separator, true); err != nil {
+					return "", err
+				}
+				if rest == "" {
+					return "", fmt.Errorf(`Unbalanced braces in %q`, glob)
+				}
+				if rest[0] == '}' {
+					break
+				}
+				pattern.WriteByte('|')
+				rest = rest[1:]
+			}
+			pattern.WriteByte(')')
+			i = len(glob) - len(rest)
+		case ',', '}':
+			if nested {
+				return glob[i:], nil
+			}
+			if c == '}' {
+				return "", fmt.Errorf(`Unbalanced braces in %q`, glob)
+			}
+			pattern.WriteByte(c)
+		default:
+			pattern.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	return "", nil
+}
+
+// IsGlobLiteral returns true if the glob pattern contains no special characters and thus only matches itself.
+func IsGlobLiteral(glob string) bool {
+	return !strings.ContainsAny(glob, GlobChars)
+}
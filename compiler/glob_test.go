@@ -0,0 +1,52 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobCompiler(t *testing.T) {
+	for k, c := range []struct {
+		glob         string
+		separator    byte
+		failCompile  bool
+		matchAgainst string
+		failMatch    bool
+	}{
+		{"resources:articles:*", ':', false, "resources:articles:1234", false},
+		{"resources:articles:*", ':', false, "resources:articles:", false},
+		{"resources:articles:*", ':', false, "resources:articles:1234:comments", true},
+		{"resources:articles:*", 0, false, "resources:articles:1234:comments", false},
+		{"resources:**", ':', false, "resources:articles:1234:comments", false},
+		{"resources:*:comments", ':', false, "resources:articles:comments", false},
+		{"resources:*:comments", ':', false, "resources:articles:1:comments", true},
+		{"users:?", ':', false, "users:a", false},
+		{"users:?", ':', false, "users:ab", true},
+		{"users:?", ':', false, "users::", true},
+		{"{get,update}", ':', false, "update", false},
+		{"{get,update}", ':', false, "delete", true},
+		{"{get,up{date,sert}}", ':', false, "upsert", false},
+		{"articles:{*:edit,list}", ':', false, "articles:1:edit", false},
+		{"articles:{*:edit,list}", ':', false, "articles:list", false},
+		{"articles:{*:edit,list}", ':', false, "articles:1:list", true},
+		{"a.b", ':', false, "axb", true},
+		{`a\*`, ':', false, "a*", false},
+		{`a\*`, ':', false, "ab", true},
+		{"a,b", ':', false, "a,b", false},
+		{"{a,b", ':', true, "", true},
+		{"a}", ':', true, "", true},
+		{`a\`, ':', true, "", true},
+	} {
+		result, err := CompileGlob(c.glob, c.separator)
+		assert.Equal(t, c.failCompile, err != nil, "Case %d: %v", k, err)
+		if c.failCompile || err != nil {
+			continue
+		}
+
+		assert.Equal(t, !c.failMatch, result.MatchString(c.matchAgainst), "Case %d: %s", k, result.String())
+	}
+
+	assert.True(t, IsGlobLiteral("resources:articles"))
+	assert.False(t, IsGlobLiteral("resources:*"))
+}
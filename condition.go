@@ -2,6 +2,7 @@ package ladon
 
 import (
 	"encoding/json"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -15,9 +16,62 @@ type Condition interface {
 	Fulfills(interface{}, *Request) bool
 }
 
+// ConditionWithError is implemented by conditions which can tell an unfulfilled condition from one that could not be
+// evaluated, e.g. because it is misconfigured. Ladon prefers Evaluate over Fulfills.
+type ConditionWithError interface {
+	Condition
+
+	// Evaluate returns true if the request is fulfilled by the condition or an error if the condition could not
+	// be evaluated.
+	Evaluate(interface{}, *Request) (bool, error)
+}
+
+// EvaluateCondition evaluates the condition with Evaluate if it implements ConditionWithError and with Fulfills
+// otherwise.
+func EvaluateCondition(c Condition, value interface{}, r *Request) (bool, error) {
+	if ce, ok := c.(ConditionWithError); ok {
+		return ce.Evaluate(value, r)
+	}
+	return c.Fulfills(value, r), nil
+}
+
+// ValidatableCondition is implemented by conditions which can check their options before they are evaluated.
+type ValidatableCondition interface {
+	Condition
+
+	// Validate returns an error if the condition's options are invalid.
+	Validate() error
+}
+
 // Conditions is a collection of conditions.
 type Conditions map[string]Condition
 
+// Validate validates every condition implementing ValidatableCondition. The error names the key of the first
+// invalid condition.
+func (cs Conditions) Validate() error {
+	for _, key := range cs.keys() {
+		c, ok := cs[key].(ValidatableCondition)
+		if !ok {
+			continue
+		}
+		if err := c.Validate(); err != nil {
+			return errors.Errorf("invalid condition %s (%s): %s", key, c.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of the conditions in sorted order, so that conditions are always processed in the
+// same order.
+func (cs Conditions) keys() []string {
+	keys := make([]string, 0, len(cs))
+	for key := range cs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // AddCondition adds a condition to the collection.
 func (cs Conditions) AddCondition(key string, c Condition) {
 	cs[key] = c
@@ -111,4 +165,49 @@ var ConditionFactories = map[string]func() Condition{
 	new(BodyArrayMatchCondition).GetName(): func() Condition {
 		return new(BodyArrayMatchCondition)
 	},
+	new(StringInCondition).GetName(): func() Condition {
+		return new(StringInCondition)
+	},
+	new(ArrayContainsCondition).GetName(): func() Condition {
+		return new(ArrayContainsCondition)
+	},
+	new(ArrayIntersectsCondition).GetName(): func() Condition {
+		return new(ArrayIntersectsCondition)
+	},
+	new(ContextCompareCondition).GetName(): func() Condition {
+		return new(ContextCompareCondition)
+	},
+	new(RequestHeaderCondition).GetName(): func() Condition {
+		return new(RequestHeaderCondition)
+	},
+	new(RequestQueryCondition).GetName(): func() Condition {
+		return new(RequestQueryCondition)
+	},
+	new(RequestMethodCondition).GetName(): func() Condition {
+		return new(RequestMethodCondition)
+	},
+	new(RequestPathCondition).GetName(): func() Condition {
+		return new(RequestPathCondition)
+	},
+	new(ClientCertificateCondition).GetName(): func() Condition {
+		return new(ClientCertificateCondition)
+	},
+	new(NumericCompareCondition).GetName(): func() Condition {
+		return new(NumericCompareCondition)
+	},
+	new(TimeWindowCondition).GetName(): func() Condition {
+		return new(TimeWindowCondition)
+	},
+	new(DateRangeCondition).GetName(): func() Condition {
+		return new(DateRangeCondition)
+	},
+	new(AndCondition).GetName(): func() Condition {
+		return new(AndCondition)
+	},
+	new(OrCondition).GetName(): func() Condition {
+		return new(OrCondition)
+	},
+	new(NotCondition).GetName(): func() Condition {
+		return new(NotCondition)
+	},
 }
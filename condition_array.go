@@ -0,0 +1,75 @@
+package ladon
+
+// ArrayContainsCondition is a condition which is fulfilled if the given
+// value is an array containing all of the values specified in ArrayContainsCondition
+type ArrayContainsCondition struct {
+	Values []string `json:"values"`
+}
+
+// Fulfills returns true if the given value is an array and every string of
+// ArrayContainsCondition.Values is one of its elements
+func (c *ArrayContainsCondition) Fulfills(value interface{}, _ *Request) bool {
+	elements, ok := stringSet(value)
+	if !ok {
+		return false
+	}
+
+	for _, v := range c.Values {
+		if _, ok := elements[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// GetName returns the condition's name.
+func (c *ArrayContainsCondition) GetName() string {
+	return "ArrayContainsCondition"
+}
+
+// ArrayIntersectsCondition is a condition which is fulfilled if the given
+// value is an array containing at least one of the values specified in ArrayIntersectsCondition
+type ArrayIntersectsCondition struct {
+	Values []string `json:"values"`
+}
+
+// Fulfills returns true if the given value is an array and at least one string
+// of ArrayIntersectsCondition.Values is one of its elements
+func (c *ArrayIntersectsCondition) Fulfills(value interface{}, _ *Request) bool {
+	elements, ok := stringSet(value)
+	if !ok {
+		return false
+	}
+
+	for _, v := range c.Values {
+		if _, ok := elements[v]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the condition's name.
+func (c *ArrayIntersectsCondition) GetName() string {
+	return "ArrayIntersectsCondition"
+}
+
+// stringSet returns the string elements of an array. Elements which are not strings are ignored.
+func stringSet(value interface{}) (map[string]struct{}, bool) {
+	set := map[string]struct{}{}
+	switch v := value.(type) {
+	case []string:
+		for _, e := range v {
+			set[e] = struct{}{}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				set[s] = struct{}{}
+			}
+		}
+	default:
+		return nil, false
+	}
+	return set, true
+}
@@ -0,0 +1,31 @@
+package ladon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayConditions(t *testing.T) {
+	groups := []interface{}{"admins", "editors", 42}
+
+	for k, c := range []struct {
+		condition Condition
+		value     interface{}
+		pass      bool
+	}{
+		{condition: &ArrayContainsCondition{Values: []string{"admins"}}, value: groups, pass: true},
+		{condition: &ArrayContainsCondition{Values: []string{"admins", "editors"}}, value: groups, pass: true},
+		{condition: &ArrayContainsCondition{Values: []string{"admins", "owners"}}, value: groups, pass: false},
+		{condition: &ArrayContainsCondition{Values: []string{"42"}}, value: groups, pass: false},
+		{condition: &ArrayContainsCondition{Values: []string{"admins"}}, value: []string{"admins"}, pass: true},
+		{condition: &ArrayContainsCondition{Values: []string{"admins"}}, value: "admins", pass: false},
+		{condition: &ArrayContainsCondition{}, value: []interface{}{}, pass: true},
+		{condition: &ArrayIntersectsCondition{Values: []string{"owners", "editors"}}, value: groups, pass: true},
+		{condition: &ArrayIntersectsCondition{Values: []string{"owners"}}, value: groups, pass: false},
+		{condition: &ArrayIntersectsCondition{Values: []string{"owners"}}, value: nil, pass: false},
+		{condition: &ArrayIntersectsCondition{}, value: groups, pass: false},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, new(Request)), "case %d", k)
+	}
+}
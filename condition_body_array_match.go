@@ -1,14 +1,9 @@
 package ladon
 
 import (
-	"bytes"
-	"encoding/json"
-	"io/ioutil"
-	"net/http"
 	"regexp"
-	"strings"
 
-	"github.com/d3sw/ladon/compiler"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -19,6 +14,9 @@ const (
 // BodyArrayMatchCondition is a condition which is fulfilled if the value at the
 // path in the body is an array and all/any of its elements match the regex
 // pattern specified in BodyArrayMatchCondition
+//
+// Elements which are not strings are matched in their json form like in
+// BodyMatchCondition, e.g. 5 as "5" and true as "true".
 type BodyArrayMatchCondition struct {
 	Mode    string `json:"mode"`
 	Path    string `json:"path"`
@@ -27,59 +25,59 @@ type BodyArrayMatchCondition struct {
 
 // Fulfills returns true if the value at the path in the body is an array and
 // all/any of its elements match the regex pattern specified in BodyMatchCondition
-func (c *BodyArrayMatchCondition) Fulfills(_ interface{}, r *Request) bool {
-	req, ok := r.Context[KeyRawRequest].(*http.Request)
+func (c *BodyArrayMatchCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the value at the path in the body is an array and
+// all/any of its elements match the regex pattern or an error if the mode or
+// pattern is invalid or the body can not be read
+func (c *BodyArrayMatchCondition) Evaluate(_ interface{}, r *Request) (bool, error) {
+	if c.Mode != Matchall && c.Mode != Matchany {
+		return false, errors.Errorf("unknown mode %q", c.Mode)
+	}
+	reg, err := cachedPattern(c.Matches)
+	if err != nil {
+		return false, err
+	}
+	v, ok, err := bodyValue(r, c.Path)
+	if err != nil || !ok {
+		return false, err
+	}
+	return matches(v, reg, c.Mode), nil
+}
+
+// matches returns true if v is an array and all/any of its elements match. Values which are not arrays never
+// match.
+func matches(v interface{}, reg *regexp.Regexp, mode string) bool {
+	elements, ok := v.([]interface{})
 	if !ok {
 		return false
 	}
-	contentType := strings.ToLower(req.Header.Get("Content-type"))
-	switch contentType {
-	case "application/json":
-		body, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			return false
+	for _, e := range elements {
+		s, err := jsonString(e)
+		br := err == nil && reg.MatchString(s)
+		if mode == Matchany && br {
+			return true
 		}
-		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-		p := &DefaultPolicy{}
-		reg, err := compiler.CompileRegex(c.Matches, p.GetStartDelimiter(), p.GetEndDelimiter())
-		if err != nil {
+		if mode == Matchall && !br {
 			return false
 		}
-		v, err := JsonQuery(body, c.Path)
-		return matches(v, reg, c.Mode)
-	default:
-		return false
 	}
+	return mode == Matchall
 }
 
-func matches(data []byte, reg *regexp.Regexp, mode string) bool {
-	var v interface{}
-	if err := json.Unmarshal(data, &v); err != nil {
-		return false
+// Validate returns an error if the mode, path or pattern is invalid.
+func (c *BodyArrayMatchCondition) Validate() error {
+	if c.Mode != Matchall && c.Mode != Matchany {
+		return errors.Errorf("unknown mode %q", c.Mode)
 	}
-	switch v.(type) {
-	case []interface{}:
-		for _, e := range v.([]interface{}) {
-			s, ok := e.(string)
-			if !ok {
-				if mode == Matchall {
-					return false
-				}
-				continue
-			}
-			br := reg.MatchString(s)
-			if mode == Matchany && br {
-				return true
-			}
-			if mode == Matchall && !br {
-				return false
-			}
-		}
-	}
-	if mode == Matchany {
-		return false
+	if _, err := CompileQuery(c.Path); err != nil {
+		return errors.WithStack(err)
 	}
-	return true
+	_, err := cachedPattern(c.Matches)
+	return err
 }
 
 // GetName returns the condition's name.
@@ -42,3 +42,58 @@ func TestJsonBodyArrayMatch(t *testing.T) {
 		assert.Equal(t, c.pass, condition.Fulfills(nil, lr), "%s", c.matches)
 	}
 }
+
+func TestBodyArrayMatchNonStrings(t *testing.T) {
+	for _, c := range []struct {
+		mode    string
+		matches string
+		path    string
+		pass    bool
+	}{
+		{mode: "all", matches: "<[0-9]+>", path: ".sizes", pass: true},
+		{mode: "all", matches: "<[0-9]>", path: ".sizes", pass: false},
+		{mode: "any", matches: "20", path: ".sizes", pass: true},
+		{mode: "all", matches: "<true|false>", path: ".flags", pass: true},
+		{mode: "any", matches: `{"b":1}`, path: ".mixed", pass: true},
+		{mode: "any", matches: "1.5", path: ".mixed", pass: true},
+		{mode: "all", matches: "<.+>", path: ".mixed", pass: true},
+	} {
+		condition := &BodyArrayMatchCondition{
+			Mode:    c.mode,
+			Path:    c.path,
+			Matches: c.matches,
+		}
+		var body = `{"sizes":[5,20,300],"flags":[true,false],"mixed":["a",1.5,null,{"b":1}]}`
+		r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/app/test", bytes.NewBuffer([]byte(body)))
+		r.Header.Set("Content-type", "application/json")
+
+		lr := &Request{Context: Context{KeyRawRequest: r}}
+		assert.Equal(t, c.pass, condition.Fulfills(nil, lr), "%s %s", c.path, c.matches)
+	}
+}
+
+func TestBodyArrayMatchMissingValues(t *testing.T) {
+	for _, body := range []string{`{}`, `{"items":[]}`, `{"items":[{"name":"a"}]}`} {
+		for _, mode := range []string{Matchall, Matchany} {
+			condition := &BodyArrayMatchCondition{Mode: mode, Path: ".items[].role", Matches: "user"}
+			r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/app/test", bytes.NewBufferString(body))
+			r.Header.Set("Content-type", "application/json")
+
+			lr := &Request{Context: Context{KeyRawRequest: r}}
+			assert.False(t, condition.Fulfills(nil, lr), "%s %s", mode, body)
+		}
+	}
+}
+
+func TestBodyArrayMatchNonArrays(t *testing.T) {
+	for _, body := range []string{`{"roles":"admin"}`, `{"roles":{"a":"admin"}}`, `{"roles":null}`, `{"roles":5}`} {
+		for _, mode := range []string{Matchall, Matchany} {
+			condition := &BodyArrayMatchCondition{Mode: mode, Path: ".roles", Matches: "user"}
+			r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/app/test", bytes.NewBufferString(body))
+			r.Header.Set("Content-type", "application/json")
+
+			lr := &Request{Context: Context{KeyRawRequest: r}}
+			assert.False(t, condition.Fulfills(nil, lr), "%s %s", mode, body)
+		}
+	}
+}
@@ -2,51 +2,209 @@ package ladon
 
 import (
 	"bytes"
-	"io/ioutil"
-	"net/http"
-	"strings"
+	"encoding/json"
+	"regexp"
+	"unicode/utf8"
 
 	"github.com/d3sw/ladon/compiler"
+	"github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
 )
 
 // BodyMatchCondition is a condition which is fulfilled if the value at the
 // path in the body matches the regex pattern specified in BodyMatchCondition
+//
+// Numbers, booleans, arrays and objects are matched in their json form, e.g.
+// 5 as "5". The value can be checked by type as well, all checks which are
+// set must hold. Matches may then be empty, otherwise an empty pattern only
+// matches empty values:
+//
+//   - Type is one of string, number, boolean, null, array and object.
+//   - Equals compares the value with a json value, numbers numerically.
+//   - Min and Max are inclusive bounds of a number or numeric string.
+//   - MinLength and MaxLength are inclusive bounds of the length of a string,
+//     array or object.
+//   - Exists set to false is fulfilled only if there is no value at the path
+//     and can not be combined with other checks. Otherwise a value must exist,
+//     which may be null. A body which can not be decoded fails with an error.
+//
+// The body of the raw http request is decoded according to its content type:
+// json, xml, yaml, url encoded forms and multipart forms are supported. Form
+// fields are strings, or arrays of strings if they repeat, and uploaded files
+// are objects holding their filename, content_type and size. Xml attributes
+// are prefixed with @ and the text of elements with attributes or children is
+// stored as #text.
 type BodyMatchCondition struct {
-	Path    string `json:"path"`
-	Matches string `json:"matches"`
+	Path      string      `json:"path"`
+	Matches   string      `json:"matches"`
+	Type      string      `json:"type,omitempty"`
+	Equals    interface{} `json:"equals,omitempty"`
+	Min       *float64    `json:"min,omitempty"`
+	Max       *float64    `json:"max,omitempty"`
+	MinLength *int        `json:"min_length,omitempty"`
+	MaxLength *int        `json:"max_length,omitempty"`
+	Exists    *bool       `json:"exists,omitempty"`
 }
 
 // Fulfills returns true if the value at the path in the body matches the regex
 // pattern specified in BodyMatchCondition
-func (c *BodyMatchCondition) Fulfills(_ interface{}, r *Request) bool {
-	req, ok := r.Context[KeyRawRequest].(*http.Request)
+func (c *BodyMatchCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the value at the path in the body matches the regex
+// pattern and passes the typed checks or an error if the condition is invalid
+// or the body can not be read
+func (c *BodyMatchCondition) Evaluate(_ interface{}, r *Request) (bool, error) {
+	reg, err := c.pattern()
+	if err != nil {
+		return false, err
+	}
+	v, ok, err := bodyValue(r, c.Path)
+	if err != nil {
+		return false, err
+	}
+	if c.Exists != nil && !*c.Exists {
+		return !ok, nil
+	}
 	if !ok {
-		return false
+		return false, nil
 	}
-	contentType := strings.ToLower(req.Header.Get("Content-type"))
-	switch contentType {
-	case "application/json":
-		body, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			return false
+
+	if c.Type != "" && jsonType(v) != c.Type {
+		return false, nil
+	}
+	if c.Equals != nil && !jsonEqual(v, c.Equals) {
+		return false, nil
+	}
+	if c.Min != nil || c.Max != nil {
+		n, ok := toFloat64(v)
+		if !ok || (c.Min != nil && n < *c.Min) || (c.Max != nil && n > *c.Max) {
+			return false, nil
 		}
-		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-		s, err := String(JsonQuery(body, c.Path))
-		if err != nil {
-			return false
+	}
+	if c.MinLength != nil || c.MaxLength != nil {
+		l, ok := jsonLength(v)
+		if !ok || (c.MinLength != nil && l < *c.MinLength) || (c.MaxLength != nil && l > *c.MaxLength) {
+			return false, nil
 		}
-		p := &DefaultPolicy{}
-		reg, err := compiler.CompileRegex(c.Matches, p.GetStartDelimiter(), p.GetEndDelimiter())
-		if err != nil {
-			return false
+	}
+	if reg != nil {
+		s, err := jsonString(v)
+		if err != nil || !reg.MatchString(s) {
+			return false, nil
 		}
-		return reg.MatchString(s)
-	default:
-		return false
 	}
+	return true, nil
+}
+
+// pattern returns the compiled pattern, which is nil if Matches is empty and other checks are set, or an error
+// if the options are invalid. The path is compiled when the body is queried.
+func (c *BodyMatchCondition) pattern() (*regexp.Regexp, error) {
+	if c.Type != "" && !jsonTypes[c.Type] {
+		return nil, errors.Errorf("unknown type %q", c.Type)
+	}
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		return nil, errors.Errorf("min %v is greater than max %v", *c.Min, *c.Max)
+	}
+	if c.MinLength != nil && c.MaxLength != nil && *c.MinLength > *c.MaxLength {
+		return nil, errors.Errorf("min_length %d is greater than max_length %d", *c.MinLength, *c.MaxLength)
+	}
+	if c.Exists != nil && !*c.Exists && (c.Matches != "" || c.hasTypedChecks()) {
+		return nil, errors.New("exists false can not be combined with other checks")
+	}
+
+	// Without typed checks an empty pattern matches only empty values, as it always did.
+	if c.Matches == "" && (c.Exists != nil || c.hasTypedChecks()) {
+		return nil, nil
+	}
+	return cachedPattern(c.Matches)
+}
+
+// hasTypedChecks returns true if a check other than Matches and Exists is set.
+func (c *BodyMatchCondition) hasTypedChecks() bool {
+	return c.Type != "" || c.Equals != nil || c.Min != nil || c.Max != nil || c.MinLength != nil || c.MaxLength != nil
+}
+
+// Validate returns an error if the path, pattern, type or bounds are invalid.
+func (c *BodyMatchCondition) Validate() error {
+	if _, err := CompileQuery(c.Path); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := c.pattern()
+	return err
 }
 
 // GetName returns the condition's name.
 func (c *BodyMatchCondition) GetName() string {
 	return "BodyMatchCondition"
 }
+
+// patterns caches compiled body patterns, because conditions store their patterns as strings.
+var patterns, _ = lru.New(512)
+
+// cachedPattern returns the pattern compiled with the default delimiters, compiling it if it is not cached.
+func cachedPattern(pattern string) (*regexp.Regexp, error) {
+	if reg, ok := patterns.Get(pattern); ok {
+		return reg.(*regexp.Regexp), nil
+	}
+	p := &DefaultPolicy{}
+	reg, err := compiler.CompileRegex(pattern, p.GetStartDelimiter(), p.GetEndDelimiter())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	patterns.Add(pattern, reg)
+	return reg, nil
+}
+
+// jsonTypes are the types returned by jsonType.
+var jsonTypes = map[string]bool{"string": true, "number": true, "boolean": true, "null": true, "array": true, "object": true}
+
+// jsonType returns the json type of a decoded value.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, ok := toFloat64(v); ok {
+		return "number"
+	}
+	return ""
+}
+
+// jsonEqual returns true if both values have the same json encoding, which compares numbers numerically and
+// maps regardless of their order.
+func jsonEqual(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+// jsonLength returns the number of characters of a string and the number of elements of an array or object.
+func jsonLength(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []interface{}:
+		return len(v), true
+	case map[string]interface{}:
+		return len(v), true
+	default:
+		return 0, false
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJsonBodyMatch(t *testing.T) {
@@ -43,3 +44,132 @@ func TestJsonBodyMatch(t *testing.T) {
 		assert.Equal(t, c.pass, condition.Fulfills(nil, lr), "%s", c.matches)
 	}
 }
+
+func TestBodyMatchTyped(t *testing.T) {
+	body := `{"amount":5,"price":12.5,"express":true,"coupon":null,"items":["a","b"],"owner":{"name":"peter"},"code":"0042"}`
+	float := func(f float64) *float64 { return &f }
+	length := func(i int) *int { return &i }
+	exists := func(b bool) *bool { return &b }
+
+	for k, c := range []struct {
+		condition *BodyMatchCondition
+		pass      bool
+	}{
+		{condition: &BodyMatchCondition{Path: ".amount", Matches: "5"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".price", Matches: "12.5"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".express", Matches: "true"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".amount", Type: "number"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".code", Type: "number"}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".express", Type: "boolean"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".coupon", Type: "null"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".missing", Type: "null"}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".items", Type: "array"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".owner", Type: "object"}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".amount", Equals: 5}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".amount", Equals: "5"}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".express", Equals: false}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".items", Equals: []string{"a", "b"}}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".owner", Equals: map[string]interface{}{"name": "peter"}}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".amount", Min: float(1), Max: float(10)}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".price", Max: float(10)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".code", Min: float(42), Max: float(42)}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".express", Min: float(0)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".items", MinLength: length(1), MaxLength: length(2)}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".code", MaxLength: length(3)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".amount", MinLength: length(0)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".coupon", Exists: exists(true)}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".coupon", Exists: exists(false)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".missing", Exists: exists(false)}, pass: true},
+		{condition: &BodyMatchCondition{Path: ".missing", Exists: exists(true)}, pass: false},
+		{condition: &BodyMatchCondition{Path: ".amount", Type: "number", Min: float(10)}, pass: false},
+	} {
+		r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", bytes.NewBufferString(body))
+		r.Header.Set("Content-type", "application/json")
+
+		lr := &Request{Context: Context{KeyRawRequest: r}}
+		pass, err := c.condition.Evaluate(nil, lr)
+		assert.NoError(t, err, "case %d", k)
+		assert.Equal(t, c.pass, pass, "case %d", k)
+	}
+
+	for k, c := range []*BodyMatchCondition{
+		{Path: ".a", Type: "integer"},
+		{Path: ".a", Min: float(2), Max: float(1)},
+		{Path: ".a", MinLength: length(2), MaxLength: length(1)},
+		{Path: ".a", Exists: exists(false), Type: "string"},
+	} {
+		assert.Error(t, c.Validate(), "case %d", k)
+	}
+}
+
+func TestBodyMatchEmptyPattern(t *testing.T) {
+	exists := true
+	for k, c := range []struct {
+		condition *BodyMatchCondition
+		body      string
+		pass      bool
+	}{
+		{condition: &BodyMatchCondition{Path: ".owner"}, body: `{"owner":"peter"}`, pass: false},
+		{condition: &BodyMatchCondition{Path: ".owner"}, body: `{"owner":""}`, pass: true},
+		{condition: &BodyMatchCondition{Path: ".owner", Type: "string"}, body: `{"owner":"peter"}`, pass: true},
+		{condition: &BodyMatchCondition{Path: ".owner", Exists: &exists}, body: `{"owner":"peter"}`, pass: true},
+	} {
+		r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", bytes.NewBufferString(c.body))
+		r.Header.Set("Content-type", "application/json")
+
+		lr := &Request{Context: Context{KeyRawRequest: r}}
+		assert.Equal(t, c.pass, c.condition.Fulfills(nil, lr), "case %d", k)
+	}
+}
+
+func TestBodyMatchExistsFalseUndecodableBody(t *testing.T) {
+	exists := false
+	c := &BodyMatchCondition{Path: ".admin", Exists: &exists}
+	for k, b := range []struct {
+		contentType string
+		body        string
+		pass        bool
+	}{
+		{contentType: "application/json", body: `{"owner":"peter"}`, pass: true},
+		{contentType: "application/json", body: "", pass: true},
+		{contentType: "application/json", body: `{"admin":true`},
+		{contentType: "", body: `{"admin":true}`},
+		{contentType: "text/plain", body: `{"admin":true}`},
+	} {
+		r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", bytes.NewBufferString(b.body))
+		r.Header.Set("Content-type", b.contentType)
+
+		pass, err := c.Evaluate(nil, &Request{Context: Context{KeyRawRequest: r}})
+		assert.Equal(t, b.pass, pass, "case %d", k)
+		assert.Equal(t, !b.pass, err != nil, "case %d", k)
+	}
+}
+
+func TestBodyPatternsAndQueriesAreCached(t *testing.T) {
+	a, err := cachedPattern("roles:<.*>")
+	require.NoError(t, err)
+	b, err := cachedPattern("roles:<.*>")
+	require.NoError(t, err)
+	assert.True(t, a == b)
+
+	qa, err := cachedQuery(".subjects[0]")
+	require.NoError(t, err)
+	qb, err := cachedQuery(".subjects[0]")
+	require.NoError(t, err)
+	assert.True(t, qa == qb)
+
+	// Invalid options are still reported when the condition is evaluated.
+	r, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/app/test", bytes.NewBufferString(`{}`))
+	r.Header.Set("Content-type", "application/json")
+	lr := &Request{Context: Context{KeyRawRequest: r}}
+	for k, c := range []ConditionWithError{
+		&BodyMatchCondition{Path: ".a[", Matches: "a"},
+		&BodyMatchCondition{Path: ".a", Matches: "<[a-z>"},
+		&BodyArrayMatchCondition{Mode: Matchall, Path: ".a[", Matches: "a"},
+		&NumericCompareCondition{Operator: "lt", Path: ".a["},
+		&ContextCompareCondition{Operator: "eq", Path: ".a["},
+	} {
+		_, err := c.Evaluate("a", lr)
+		assert.Error(t, err, "case %d", k)
+	}
+}
@@ -2,6 +2,8 @@ package ladon
 
 import (
 	"net"
+
+	"github.com/pkg/errors"
 )
 
 // CIDRCondition makes sure that the warden requests' IP address is in the given CIDR.
@@ -10,23 +12,35 @@ type CIDRCondition struct {
 }
 
 // Fulfills returns true if the the request is fulfilled by the condition.
-func (c *CIDRCondition) Fulfills(value interface{}, _ *Request) bool {
-	ips, ok := value.(string)
-	if !ok {
-		return false
-	}
+func (c *CIDRCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
 
+// Evaluate returns true if the the request is fulfilled by the condition or an error if the CIDR is invalid.
+func (c *CIDRCondition) Evaluate(value interface{}, _ *Request) (bool, error) {
 	_, cidrnet, err := net.ParseCIDR(c.CIDR)
 	if err != nil {
-		return false
+		return false, errors.WithStack(err)
+	}
+
+	ips, ok := value.(string)
+	if !ok {
+		return false, nil
 	}
 
 	ip := net.ParseIP(ips)
 	if ip == nil {
-		return false
+		return false, nil
 	}
 
-	return cidrnet.Contains(ip)
+	return cidrnet.Contains(ip), nil
+}
+
+// Validate returns an error if the CIDR is invalid.
+func (c *CIDRCondition) Validate() error {
+	_, _, err := net.ParseCIDR(c.CIDR)
+	return errors.WithStack(err)
 }
 
 // GetName returns the condition's name.
@@ -0,0 +1,163 @@
+package ladon
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// AndCondition is fulfilled if all of its nested conditions are fulfilled. Like the conditions of a policy,
+// every nested condition is evaluated against the request's context value of its own key.
+type AndCondition struct {
+	Conditions Conditions `json:"conditions"`
+}
+
+// Fulfills returns true if all nested conditions are fulfilled. The value is ignored.
+func (c *AndCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if all nested conditions are fulfilled or the error of the first nested condition,
+// in order of their keys, which could not be evaluated.
+func (c *AndCondition) Evaluate(_ interface{}, r *Request) (bool, error) {
+	return evaluateNestedConditions(c.Conditions, r, true)
+}
+
+// evaluateNestedConditions evaluates all conditions in order of their keys. It returns whether all, or if
+// all is false at least one, of them are fulfilled, or the first error. All conditions are evaluated, so the
+// result does not depend on which of them fails first.
+func evaluateNestedConditions(cs Conditions, r *Request, all bool) (bool, error) {
+	result := all
+	for _, key := range cs.keys() {
+		pass, err := evaluateNestedCondition(key, cs[key], r)
+		if err != nil {
+			return false, err
+		}
+		if pass != all {
+			result = !all
+		}
+	}
+	return result, nil
+}
+
+// Validate validates the nested conditions.
+func (c *AndCondition) Validate() error {
+	return c.Conditions.Validate()
+}
+
+// GetName returns the condition's name.
+func (c *AndCondition) GetName() string {
+	return "AndCondition"
+}
+
+// UnmarshalJSON unmarshals the condition and its nested conditions from json.
+func (c *AndCondition) UnmarshalJSON(data []byte) error {
+	cs, err := unmarshalNestedConditions(data)
+	c.Conditions = cs
+	return err
+}
+
+// OrCondition is fulfilled if at least one of its nested conditions is fulfilled. Every nested condition is
+// evaluated against the request's context value of its own key.
+type OrCondition struct {
+	Conditions Conditions `json:"conditions"`
+}
+
+// Fulfills returns true if at least one nested condition is fulfilled. The value is ignored.
+func (c *OrCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if at least one nested condition is fulfilled or the error of the first nested
+// condition, in order of their keys, which could not be evaluated. An error wins over a fulfilled condition,
+// so a broken condition never grants access.
+func (c *OrCondition) Evaluate(_ interface{}, r *Request) (bool, error) {
+	return evaluateNestedConditions(c.Conditions, r, false)
+}
+
+// Validate validates the nested conditions.
+func (c *OrCondition) Validate() error {
+	return c.Conditions.Validate()
+}
+
+// GetName returns the condition's name.
+func (c *OrCondition) GetName() string {
+	return "OrCondition"
+}
+
+// UnmarshalJSON unmarshals the condition and its nested conditions from json.
+func (c *OrCondition) UnmarshalJSON(data []byte) error {
+	cs, err := unmarshalNestedConditions(data)
+	c.Conditions = cs
+	return err
+}
+
+// NotCondition negates its nested conditions: it is fulfilled unless all of them are fulfilled. Usually it
+// wraps a single condition, which is evaluated against the request's context value of its own key.
+type NotCondition struct {
+	Conditions Conditions `json:"conditions"`
+}
+
+// Fulfills returns true if at least one nested condition is not fulfilled. The value is ignored.
+func (c *NotCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if at least one nested condition is not fulfilled. A nested condition which could
+// not be evaluated makes the negation fail rather than pass.
+func (c *NotCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	pass, err := (&AndCondition{Conditions: c.Conditions}).Evaluate(value, r)
+	if err != nil {
+		return false, err
+	}
+	return !pass, nil
+}
+
+// Validate validates the nested conditions.
+func (c *NotCondition) Validate() error {
+	return c.Conditions.Validate()
+}
+
+// GetName returns the condition's name.
+func (c *NotCondition) GetName() string {
+	return "NotCondition"
+}
+
+// UnmarshalJSON unmarshals the condition and its nested conditions from json.
+func (c *NotCondition) UnmarshalJSON(data []byte) error {
+	cs, err := unmarshalNestedConditions(data)
+	c.Conditions = cs
+	return err
+}
+
+// evaluateNestedCondition evaluates a nested condition against the context value of its key.
+func evaluateNestedCondition(key string, c Condition, r *Request) (bool, error) {
+	pass, err := EvaluateCondition(c, r.Context[key], r)
+	if err != nil {
+		return false, errors.Wrapf(err, "condition %s (%s)", key, c.GetName())
+	}
+	return pass, nil
+}
+
+// unmarshalNestedConditions decodes the options of a composite condition. Conditions.UnmarshalJSON refuses
+// to decode into a nil map, which is what json would hand it.
+func unmarshalNestedConditions(data []byte) (Conditions, error) {
+	var raw struct {
+		Conditions json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cs := Conditions{}
+	if len(raw.Conditions) == 0 || string(raw.Conditions) == "null" {
+		return cs, nil
+	}
+	if err := cs.UnmarshalJSON(raw.Conditions); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
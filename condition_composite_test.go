@@ -0,0 +1,97 @@
+package ladon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeConditions(t *testing.T) {
+	officeOrMFA := &OrCondition{Conditions: Conditions{
+		"remoteIP": &CIDRCondition{CIDR: "10.0.0.0/8"},
+		"mfa":      &StringEqualCondition{Equals: "true"},
+	}}
+	notGuest := &NotCondition{Conditions: Conditions{
+		"role": &StringEqualCondition{Equals: "guest"},
+	}}
+	both := &AndCondition{Conditions: Conditions{
+		"access": officeOrMFA,
+		"role":   notGuest,
+	}}
+
+	for k, c := range []struct {
+		ctx Context
+		or  bool
+		not bool
+		and bool
+	}{
+		{ctx: Context{"remoteIP": "10.1.2.3"}, or: true, not: true, and: true},
+		{ctx: Context{"remoteIP": "192.168.1.1", "mfa": "true"}, or: true, not: true, and: true},
+		{ctx: Context{"remoteIP": "192.168.1.1"}, or: false, not: true, and: false},
+		{ctx: Context{"remoteIP": "10.1.2.3", "role": "guest"}, or: true, not: false, and: false},
+		{ctx: Context{}, or: false, not: true, and: false},
+	} {
+		r := &Request{Context: c.ctx}
+		assert.Equal(t, c.or, officeOrMFA.Fulfills(nil, r), "case %d", k)
+		assert.Equal(t, c.not, notGuest.Fulfills(nil, r), "case %d", k)
+		assert.Equal(t, c.and, both.Fulfills(nil, r), "case %d", k)
+	}
+
+	assert.True(t, new(AndCondition).Fulfills(nil, new(Request)))
+	assert.False(t, new(OrCondition).Fulfills(nil, new(Request)))
+	assert.False(t, new(NotCondition).Fulfills(nil, new(Request)))
+
+	out, err := json.Marshal(Conditions{"access": both})
+	require.NoError(t, err)
+
+	cs := Conditions{}
+	require.NoError(t, json.Unmarshal(out, &cs))
+	assert.Equal(t, Conditions{"access": both}, cs)
+}
+
+func TestCompositeConditionsUnmarshal(t *testing.T) {
+	cs := Conditions{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+	"access": {
+		"type": "OrCondition",
+		"options": {
+			"conditions": {
+				"remoteIP": {"type": "CIDRCondition", "options": {"cidr": "10.0.0.0/8"}},
+				"mfa": {"type": "NotCondition", "options": {"conditions": {
+					"mfa": {"type": "StringEqualCondition", "options": {"equals": "false"}}
+				}}}
+			}
+		}
+	},
+	"empty": {
+		"type": "AndCondition"
+	}
+}`), &cs))
+
+	require.IsType(t, &OrCondition{}, cs["access"])
+	or := cs["access"].(*OrCondition)
+	assert.Equal(t, &CIDRCondition{CIDR: "10.0.0.0/8"}, or.Conditions["remoteIP"])
+	assert.Equal(t, &NotCondition{Conditions: Conditions{"mfa": &StringEqualCondition{Equals: "false"}}}, or.Conditions["mfa"])
+	assert.Equal(t, &AndCondition{}, cs["empty"])
+
+	assert.Error(t, json.Unmarshal([]byte(`{"access": {"type": "AndCondition", "options": {"conditions": {"a": {"type": "DoesntExist"}}}}}`), &Conditions{}))
+}
+
+func TestCompositeConditionsErrorsAreDeterministic(t *testing.T) {
+	r := &Request{Context: Context{"a": "10.0.0.1", "b": "10.0.0.1"}}
+	broken := &CIDRCondition{CIDR: "10.0.0.0/80"}
+	for i := 0; i < 50; i++ {
+		for k, c := range []ConditionWithError{
+			&OrCondition{Conditions: Conditions{"a": &CIDRCondition{CIDR: "10.0.0.0/8"}, "b": broken}},
+			&OrCondition{Conditions: Conditions{"a": broken, "b": &CIDRCondition{CIDR: "10.0.0.0/8"}}},
+			&AndCondition{Conditions: Conditions{"a": &CIDRCondition{CIDR: "192.168.0.0/16"}, "b": broken}},
+			&NotCondition{Conditions: Conditions{"a": &CIDRCondition{CIDR: "192.168.0.0/16"}, "b": broken}},
+		} {
+			pass, err := c.Evaluate(nil, r)
+			assert.Error(t, err, "case %d", k)
+			assert.False(t, pass, "case %d", k)
+		}
+	}
+}
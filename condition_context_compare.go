@@ -0,0 +1,115 @@
+package ladon
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ContextCompareCondition is a condition which is fulfilled if the given value compares to another value of the
+// request according to Operator. The other value is the context value of Key or, if Path is set, the value at
+// Path in the body of the raw http request (see BodyMatchCondition).
+//
+// Operator is one of eq, ne, lt, lte, gt and gte. lt, lte, gt and gte compare numbers, including numeric strings,
+// numerically and other strings lexically. eq and ne compare two strings as strings, a number with a number or
+// numeric string numerically and any other values for deep equality. Missing values never compare.
+type ContextCompareCondition struct {
+	Key      string `json:"key,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Operator string `json:"operator"`
+}
+
+// Fulfills returns true if the given value and the referenced value compare according to the operator.
+func (c *ContextCompareCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the given value and the referenced value compare according to the operator or an
+// error if the operator is unknown or the body can not be read.
+func (c *ContextCompareCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	if err := c.validateOperator(); err != nil {
+		return false, err
+	}
+
+	other, ok, err := c.other(r)
+	if err != nil || !ok || value == nil || other == nil {
+		return false, err
+	}
+	return c.compare(value, other), nil
+}
+
+func (c *ContextCompareCondition) compare(value, other interface{}) bool {
+	a, aok := value.(string)
+	b, bok := other.(string)
+	if af, ok := toFloat64(value); ok {
+		if bf, ok := toFloat64(other); ok && !(aok && bok && c.equality()) {
+			return compareNumbers(c.Operator, af, bf)
+		}
+	}
+
+	if aok && bok {
+		switch c.Operator {
+		case "lt":
+			return a < b
+		case "lte":
+			return a <= b
+		case "gt":
+			return a > b
+		case "gte":
+			return a >= b
+		}
+	}
+
+	switch c.Operator {
+	case "eq":
+		return reflect.DeepEqual(value, other)
+	case "ne":
+		return !reflect.DeepEqual(value, other)
+	default:
+		return false
+	}
+}
+
+// Validate returns an error if the operator is unknown or neither Key nor Path is set.
+func (c *ContextCompareCondition) Validate() error {
+	if err := c.validateOperator(); err != nil {
+		return err
+	}
+	if c.Path != "" {
+		if _, err := CompileQuery(c.Path); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// validateOperator returns an error if the operator is unknown or neither Key nor Path is set. The path is
+// compiled when the body is queried.
+func (c *ContextCompareCondition) validateOperator() error {
+	if !compareOperators[c.Operator] {
+		return errors.Errorf("unknown operator %q", c.Operator)
+	}
+	if c.Key == "" && c.Path == "" {
+		return errors.New("either key or path is required")
+	}
+	return nil
+}
+
+// GetName returns the condition's name.
+func (c *ContextCompareCondition) GetName() string {
+	return "ContextCompareCondition"
+}
+
+// equality returns true if the operator tests for (in)equality.
+func (c *ContextCompareCondition) equality() bool {
+	return c.Operator == "eq" || c.Operator == "ne"
+}
+
+func (c *ContextCompareCondition) other(r *Request) (interface{}, bool, error) {
+	if c.Path == "" {
+		v, ok := r.Context[c.Key]
+		return v, ok, nil
+	}
+	return bodyValue(r, c.Path)
+}
@@ -0,0 +1,63 @@
+package ladon
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCompareCondition(t *testing.T) {
+	ctx := Context{
+		"requesterTenant": "acme",
+		"limit":           float64(100),
+		"limitString":     "100",
+		"since":           "2017-06-01",
+		"roles":           []interface{}{"admin"},
+		"zero":            "007",
+	}
+
+	for k, c := range []struct {
+		condition *ContextCompareCondition
+		value     interface{}
+		pass      bool
+	}{
+		{condition: &ContextCompareCondition{Key: "requesterTenant", Operator: "eq"}, value: "acme", pass: true},
+		{condition: &ContextCompareCondition{Key: "requesterTenant", Operator: "eq"}, value: "globex", pass: false},
+		{condition: &ContextCompareCondition{Key: "requesterTenant", Operator: "ne"}, value: "globex", pass: true},
+		{condition: &ContextCompareCondition{Key: "missing", Operator: "ne"}, value: "globex", pass: false},
+		{condition: &ContextCompareCondition{Key: "requesterTenant", Operator: "eq"}, value: nil, pass: false},
+		{condition: &ContextCompareCondition{Key: "limit", Operator: "lte"}, value: 99, pass: true},
+		{condition: &ContextCompareCondition{Key: "limit", Operator: "lte"}, value: "101", pass: false},
+		{condition: &ContextCompareCondition{Key: "limitString", Operator: "gt"}, value: "20", pass: false},
+		{condition: &ContextCompareCondition{Key: "limitString", Operator: "eq"}, value: 100, pass: true},
+		{condition: &ContextCompareCondition{Key: "zero", Operator: "eq"}, value: "7", pass: false},
+		{condition: &ContextCompareCondition{Key: "since", Operator: "gte"}, value: "2017-07-15", pass: true},
+		{condition: &ContextCompareCondition{Key: "roles", Operator: "eq"}, value: []interface{}{"admin"}, pass: true},
+		{condition: &ContextCompareCondition{Key: "roles", Operator: "lt"}, value: []interface{}{"admin"}, pass: false},
+		{condition: &ContextCompareCondition{Key: "requesterTenant", Operator: "like"}, value: "acme", pass: false},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, &Request{Context: ctx}), "case %d", k)
+	}
+}
+
+func TestContextCompareConditionBody(t *testing.T) {
+	for k, c := range []struct {
+		condition *ContextCompareCondition
+		value     interface{}
+		pass      bool
+	}{
+		{condition: &ContextCompareCondition{Path: ".owner.tenant", Operator: "eq"}, value: "acme", pass: true},
+		{condition: &ContextCompareCondition{Path: ".owner.tenant", Operator: "eq"}, value: "globex", pass: false},
+		{condition: &ContextCompareCondition{Path: ".amount", Operator: "lt"}, value: 10, pass: true},
+		{condition: &ContextCompareCondition{Path: ".missing", Operator: "ne"}, value: "acme", pass: false},
+	} {
+		body := `{"owner": {"tenant": "acme"}, "amount": 12.5}`
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/documents", bytes.NewBufferString(body))
+		req.Header.Set("Content-type", "application/json")
+
+		r := &Request{Context: Context{KeyRawRequest: req}}
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, r), "case %d", k)
+	}
+}
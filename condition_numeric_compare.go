@@ -0,0 +1,152 @@
+package ladon
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NumericCompareCondition is a condition which is fulfilled if the given value is a number which compares to
+// Value according to Operator. Operator is one of lt, lte, gt, gte, eq and ne. The operator between is fulfilled if
+// the number is within Min and Max, inclusively.
+//
+// Numbers may be given as JSON numbers, Go numeric types or numeric strings. If Path is set, the number is not
+// taken from the context value, but extracted from the body of the raw http request (see BodyMatchCondition).
+type NumericCompareCondition struct {
+	Operator string  `json:"operator"`
+	Value    float64 `json:"value,omitempty"`
+	Min      float64 `json:"min,omitempty"`
+	Max      float64 `json:"max,omitempty"`
+	Path     string  `json:"path,omitempty"`
+}
+
+// Fulfills returns true if the given value, or the value at Path in the body, is a number and the comparison
+// holds.
+func (c *NumericCompareCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the given value, or the value at Path in the body, is a number and the comparison
+// holds or an error if the operator is unknown or the body can not be read.
+func (c *NumericCompareCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	if err := c.validateOperator(); err != nil {
+		return false, err
+	}
+
+	if c.Path != "" {
+		var ok bool
+		var err error
+		if value, ok, err = bodyValue(r, c.Path); err != nil || !ok {
+			return false, err
+		}
+	}
+
+	n, ok := toFloat64(value)
+	if !ok {
+		return false, nil
+	}
+
+	if c.Operator == "between" {
+		return n >= c.Min && n <= c.Max, nil
+	}
+	return compareNumbers(c.Operator, n, c.Value), nil
+}
+
+// compareOperators are the operators understood by compareNumbers.
+var compareOperators = map[string]bool{"lt": true, "lte": true, "gt": true, "gte": true, "eq": true, "ne": true}
+
+// compareNumbers returns true if a compares to b according to the operator, which is one of lt, lte, gt,
+// gte, eq and ne.
+func compareNumbers(operator string, a, b float64) bool {
+	switch operator {
+	case "lt":
+		return a < b
+	case "lte":
+		return a <= b
+	case "gt":
+		return a > b
+	case "gte":
+		return a >= b
+	case "eq":
+		return a == b
+	case "ne":
+		return a != b
+	default:
+		return false
+	}
+}
+
+// Validate returns an error if the operator is unknown, Min is greater than Max or the path is invalid.
+func (c *NumericCompareCondition) Validate() error {
+	if err := c.validateOperator(); err != nil {
+		return err
+	}
+	if c.Path != "" {
+		if _, err := CompileQuery(c.Path); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// validateOperator returns an error if the operator is unknown or Min is greater than Max. The path is
+// compiled when the body is queried.
+func (c *NumericCompareCondition) validateOperator() error {
+	if c.Operator == "between" {
+		if c.Min > c.Max {
+			return errors.Errorf("min %v is greater than max %v", c.Min, c.Max)
+		}
+	} else if !compareOperators[c.Operator] {
+		return errors.Errorf("unknown operator %q", c.Operator)
+	}
+	return nil
+}
+
+// GetName returns the condition's name.
+func (c *NumericCompareCondition) GetName() string {
+	return "NumericCompareCondition"
+}
+
+// toFloat64 converts numbers and numeric strings to float64.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v)
+	case float32:
+		return float64(v), !math.IsNaN(float64(v))
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		return toFloat64(string(v))
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return toFloat64(f)
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,58 @@
+package ladon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericCompareCondition(t *testing.T) {
+	for k, c := range []struct {
+		condition *NumericCompareCondition
+		value     interface{}
+		pass      bool
+	}{
+		{condition: &NumericCompareCondition{Operator: "lte", Value: 1000}, value: float64(1000), pass: true},
+		{condition: &NumericCompareCondition{Operator: "lte", Value: 1000}, value: 1001, pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: int64(49), pass: true},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: uint8(50), pass: false},
+		{condition: &NumericCompareCondition{Operator: "gt", Value: 0}, value: "0.5", pass: true},
+		{condition: &NumericCompareCondition{Operator: "gt", Value: 0}, value: " -1 ", pass: false},
+		{condition: &NumericCompareCondition{Operator: "gte", Value: 18}, value: json.Number("18"), pass: true},
+		{condition: &NumericCompareCondition{Operator: "eq", Value: 3}, value: float32(3), pass: true},
+		{condition: &NumericCompareCondition{Operator: "eq", Value: 3}, value: "3.1", pass: false},
+		{condition: &NumericCompareCondition{Operator: "between", Min: 1, Max: 5}, value: 1, pass: true},
+		{condition: &NumericCompareCondition{Operator: "between", Min: 1, Max: 5}, value: 5, pass: true},
+		{condition: &NumericCompareCondition{Operator: "between", Min: 1, Max: 5}, value: 5.5, pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: "abc", pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: "NaN", pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: nil, pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50}, value: true, pass: false},
+		{condition: &NumericCompareCondition{Operator: "neq", Value: 50}, value: 1, pass: false},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, new(Request)), "case %d", k)
+	}
+}
+
+func TestNumericCompareConditionBody(t *testing.T) {
+	for k, c := range []struct {
+		condition *NumericCompareCondition
+		pass      bool
+	}{
+		{condition: &NumericCompareCondition{Operator: "lte", Value: 1000, Path: ".order.amount"}, pass: true},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 500, Path: ".order.amount"}, pass: false},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 50, Path: ".risk[1]"}, pass: true},
+		{condition: &NumericCompareCondition{Operator: "eq", Value: 7, Path: ".quantity"}, pass: true},
+		{condition: &NumericCompareCondition{Operator: "eq", Value: 7, Path: ".missing"}, pass: false},
+	} {
+		body := `{"order": {"amount": 999.5}, "risk": [80, 20], "quantity": "7"}`
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-type", "application/json")
+
+		r := &Request{Context: Context{KeyRawRequest: req}}
+		assert.Equal(t, c.pass, c.condition.Fulfills(nil, r), "case %d", k)
+	}
+}
@@ -0,0 +1,316 @@
+package ladon
+
+import (
+	"crypto/x509"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+// The conditions in this file match attributes of the raw http request in the request's context (see
+// KeyRawRequest). They are not fulfilled if there is no raw http request or the attribute is missing.
+//
+// Attribute values are compared in the same way by all of them: if Matches is set, the values must match the
+// regular expression as a whole, e.g. "acme" only matches "acme" and "acme-.*" matches "acme-eu". Unlike the
+// patterns of policies and BodyMatchCondition, the expression is not enclosed in delimiters. Otherwise they
+// must equal the value of Variable (see Variables) or, if Variable is not set, the given context value, e.g. a
+// tenant passed by the caller.
+
+// RequestHeaderCondition is a condition which is fulfilled if all values of the http header Name match.
+type RequestHeaderCondition struct {
+	Name     string `json:"name"`
+	Matches  string `json:"matches,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
+// Fulfills returns true if all values of the header match.
+func (c *RequestHeaderCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if all values of the header match or an error if the pattern is invalid.
+func (c *RequestHeaderCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	req, ok := rawRequest(r)
+	if !ok {
+		return false, nil
+	}
+	return matchAttribute(req.Header[http.CanonicalHeaderKey(c.Name)], true, c.Matches, c.Variable, value, r)
+}
+
+// Validate returns an error if the name is missing or the pattern is invalid.
+func (c *RequestHeaderCondition) Validate() error {
+	if c.Name == "" {
+		return errors.New("missing header name")
+	}
+	return validateAttributeMatch(c.Matches)
+}
+
+// GetName returns the condition's name.
+func (c *RequestHeaderCondition) GetName() string {
+	return "RequestHeaderCondition"
+}
+
+// RequestQueryCondition is a condition which is fulfilled if all values of the url query parameter Name match.
+type RequestQueryCondition struct {
+	Name     string `json:"name"`
+	Matches  string `json:"matches,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
+// Fulfills returns true if all values of the query parameter match.
+func (c *RequestQueryCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if all values of the query parameter match or an error if the pattern is invalid.
+func (c *RequestQueryCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	req, ok := rawRequest(r)
+	if !ok || req.URL == nil {
+		return false, nil
+	}
+	return matchAttribute(req.URL.Query()[c.Name], true, c.Matches, c.Variable, value, r)
+}
+
+// Validate returns an error if the name is missing or the pattern is invalid.
+func (c *RequestQueryCondition) Validate() error {
+	if c.Name == "" {
+		return errors.New("missing query parameter name")
+	}
+	return validateAttributeMatch(c.Matches)
+}
+
+// GetName returns the condition's name.
+func (c *RequestQueryCondition) GetName() string {
+	return "RequestQueryCondition"
+}
+
+// RequestMethodCondition is a condition which is fulfilled if the http method is one of Methods.
+type RequestMethodCondition struct {
+	Methods []string `json:"methods"`
+}
+
+// Fulfills returns true if the http method is one of Methods, ignoring case.
+func (c *RequestMethodCondition) Fulfills(_ interface{}, r *Request) bool {
+	req, ok := rawRequest(r)
+	if !ok {
+		return false
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	for _, m := range c.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if no method is given.
+func (c *RequestMethodCondition) Validate() error {
+	if len(c.Methods) == 0 {
+		return errors.New("missing methods")
+	}
+	return nil
+}
+
+// GetName returns the condition's name.
+func (c *RequestMethodCondition) GetName() string {
+	return "RequestMethodCondition"
+}
+
+// RequestPathCondition is a condition which is fulfilled if the url path matches. If Segment is set, only the
+// segment at that index matches, counting from 0 after the leading slash. Negative indexes count from the end,
+// e.g. -1 is the last segment. The path is cleaned first (see path.Clean), so "/a/./b/../c/" is matched as
+// "/a/c".
+type RequestPathCondition struct {
+	Segment  *int   `json:"segment,omitempty"`
+	Matches  string `json:"matches,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
+// Fulfills returns true if the path or its segment matches.
+func (c *RequestPathCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the path or its segment matches or an error if the pattern is invalid.
+func (c *RequestPathCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	req, ok := rawRequest(r)
+	if !ok || req.URL == nil {
+		return false, nil
+	}
+
+	// Resolve dot segments and repeated slashes like routers do, otherwise "/tenants/acme/../other" would pass
+	// as a path of tenant acme.
+	p := path.Clean("/" + req.URL.Path)
+	values := []string{p}
+	if c.Segment != nil {
+		segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+		i := *c.Segment
+		if i < 0 {
+			i += len(segments)
+		}
+		if i < 0 || i >= len(segments) {
+			return false, nil
+		}
+		values = []string{segments[i]}
+	}
+	return matchAttribute(values, true, c.Matches, c.Variable, value, r)
+}
+
+// Validate returns an error if the pattern is invalid.
+func (c *RequestPathCondition) Validate() error {
+	return validateAttributeMatch(c.Matches)
+}
+
+// GetName returns the condition's name.
+func (c *RequestPathCondition) GetName() string {
+	return "RequestPathCondition"
+}
+
+// clientCertificateFields returns the values of the fields of a certificate ClientCertificateCondition
+// understands.
+var clientCertificateFields = map[string]func(c *x509.Certificate) []string{
+	"common_name": func(c *x509.Certificate) []string {
+		if c.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{c.Subject.CommonName}
+	},
+	"organization":        func(c *x509.Certificate) []string { return c.Subject.Organization },
+	"organizational_unit": func(c *x509.Certificate) []string { return c.Subject.OrganizationalUnit },
+	"dns_names":           func(c *x509.Certificate) []string { return c.DNSNames },
+	"email_addresses":     func(c *x509.Certificate) []string { return c.EmailAddresses },
+	"ip_addresses": func(c *x509.Certificate) []string {
+		values := make([]string, len(c.IPAddresses))
+		for i, ip := range c.IPAddresses {
+			values[i] = ip.String()
+		}
+		return values
+	},
+	"uris": func(c *x509.Certificate) []string {
+		values := make([]string, len(c.URIs))
+		for i, u := range c.URIs {
+			values[i] = u.String()
+		}
+		return values
+	},
+}
+
+// ClientCertificateCondition is a condition which is fulfilled if one of the values of Field of the verified
+// tls client certificate matches. Field is one of common_name, organization, organizational_unit and the
+// subject alternative names dns_names, email_addresses, ip_addresses and uris.
+//
+// Only certificates verified by the tls server are considered, so the server must be configured with
+// tls.VerifyClientCertIfGiven or tls.RequireAndVerifyClientCert.
+type ClientCertificateCondition struct {
+	Field    string `json:"field"`
+	Matches  string `json:"matches,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
+// Fulfills returns true if one of the values of the certificate's field matches.
+func (c *ClientCertificateCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if one of the values of the certificate's field matches or an error if the field is
+// unknown or the pattern is invalid.
+func (c *ClientCertificateCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	field, ok := clientCertificateFields[c.Field]
+	if !ok {
+		return false, errors.Errorf("unknown certificate field %q", c.Field)
+	}
+	req, ok := rawRequest(r)
+	if !ok || req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return false, nil
+	}
+	return matchAttribute(field(req.TLS.VerifiedChains[0][0]), false, c.Matches, c.Variable, value, r)
+}
+
+// Validate returns an error if the field is unknown or the pattern is invalid.
+func (c *ClientCertificateCondition) Validate() error {
+	if _, ok := clientCertificateFields[c.Field]; !ok {
+		return errors.Errorf("unknown certificate field %q", c.Field)
+	}
+	return validateAttributeMatch(c.Matches)
+}
+
+// GetName returns the condition's name.
+func (c *ClientCertificateCondition) GetName() string {
+	return "ClientCertificateCondition"
+}
+
+// rawRequest returns the raw http request in the request's context.
+func rawRequest(r *Request) (*http.Request, bool) {
+	req, ok := r.Context[KeyRawRequest].(*http.Request)
+	return req, ok && req != nil
+}
+
+// matchAttribute returns true if all, or if all is false at least one, of the values match the pattern or,
+// if the pattern is empty, equal the value of the variable or the given context value. It returns false if
+// there are no values.
+func matchAttribute(values []string, all bool, matches, variable string, value interface{}, r *Request) (bool, error) {
+	var match func(string) bool
+	if matches != "" {
+		reg, err := attributePattern(matches)
+		if err != nil {
+			return false, err
+		}
+		match = reg.MatchString
+	} else {
+		var expected string
+		var ok bool
+		if variable != "" {
+			expected, ok = r.Variables[variable]
+		} else {
+			expected, ok = value.(string)
+		}
+		if !ok {
+			return false, nil
+		}
+		match = func(s string) bool { return s == expected }
+	}
+
+	if len(values) == 0 {
+		return false, nil
+	}
+	for _, v := range values {
+		if match(v) != all {
+			return !all, nil
+		}
+	}
+	return all, nil
+}
+
+func validateAttributeMatch(matches string) error {
+	_, err := attributePattern(matches)
+	return err
+}
+
+// attributePatterns caches compiled attribute patterns.
+var attributePatterns, _ = lru.New(512)
+
+// attributePattern compiles the regular expression so that it has to match the whole value, e.g. "acme"
+// does not match "evil-acme".
+func attributePattern(matches string) (*regexp.Regexp, error) {
+	if reg, ok := attributePatterns.Get(matches); ok {
+		return reg.(*regexp.Regexp), nil
+	}
+	reg, err := regexp.Compile("^(?:" + matches + ")$")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	attributePatterns.Add(matches, reg)
+	return reg, nil
+}
@@ -0,0 +1,117 @@
+package ladon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestConditions(t *testing.T) {
+	req, _ := http.NewRequest("DELETE", "https://fuac.xxx.xxx.xxx/v1/tenants/acme/documents/12?version=3&tag=a&tag=b", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Add("X-Role", "reader")
+	req.Header.Add("X-Role", "writer")
+	spiffe, _ := url.Parse("spiffe://acme/billing")
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{
+		Subject:        pkix.Name{CommonName: "billing.acme", Organization: []string{"acme"}},
+		DNSNames:       []string{"billing.acme", "billing.internal"},
+		EmailAddresses: []string{"ops@acme"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.7")},
+		URIs:           []*url.URL{spiffe},
+	}}}}
+
+	dotted, _ := http.NewRequest("GET", "https://fuac.xxx.xxx.xxx/v1/tenants/acme/../other//documents/./12/", nil)
+	unverified, _ := http.NewRequest("GET", "https://fuac.xxx.xxx.xxx/v1", nil)
+	unverified.TLS = &tls.ConnectionState{PeerCertificates: req.TLS.VerifiedChains[0]}
+
+	segment := func(i int) *int { return &i }
+	r := &Request{
+		Context:   Context{KeyRawRequest: req},
+		Variables: Variables{"resource.tenant": "acme", "resource.id": "12"},
+	}
+
+	for k, c := range []struct {
+		condition Condition
+		value     interface{}
+		r         *Request
+		pass      bool
+	}{
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Variable: "resource.tenant"}, pass: true},
+		{condition: &RequestHeaderCondition{Name: "x-tenant", Variable: "resource.id"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant"}, value: "acme", pass: true},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant"}, value: "other", pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Variable: "resource.missing"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Role", Matches: "^(reader|writer)$"}, pass: true},
+		{condition: &RequestHeaderCondition{Name: "X-Role", Matches: "^reader$"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Matches: "acme"}, pass: true},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Matches: "acm"}, pass: false},
+		{condition: &RequestPathCondition{Segment: segment(2), Matches: "cme"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Role", Matches: "reader|writer"}, pass: true},
+		{condition: &RequestPathCondition{Matches: "/v1/tenants"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Missing", Matches: ".*"}, pass: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Matches: ".*"}, r: &Request{Context: Context{}}, pass: false},
+
+		{condition: &RequestQueryCondition{Name: "version", Matches: "^[0-9]+$"}, pass: true},
+		{condition: &RequestQueryCondition{Name: "version"}, value: "3", pass: true},
+		{condition: &RequestQueryCondition{Name: "tag", Matches: "^a$"}, pass: false},
+		{condition: &RequestQueryCondition{Name: "missing", Matches: ".*"}, pass: false},
+
+		{condition: &RequestMethodCondition{Methods: []string{"GET", "delete"}}, pass: true},
+		{condition: &RequestMethodCondition{Methods: []string{"GET"}}, pass: false},
+
+		{condition: &RequestPathCondition{Matches: "/v1/tenants/.*"}, pass: true},
+		{condition: &RequestPathCondition{Segment: segment(2), Variable: "resource.tenant"}, pass: true},
+		{condition: &RequestPathCondition{Segment: segment(-1), Variable: "resource.id"}, pass: true},
+		{condition: &RequestPathCondition{Segment: segment(0)}, value: "v2", pass: false},
+		{condition: &RequestPathCondition{Segment: segment(5), Matches: ".*"}, pass: false},
+		{condition: &RequestPathCondition{Segment: segment(-6), Matches: ".*"}, pass: false},
+		{condition: &RequestPathCondition{Segment: segment(2), Matches: "acme"}, r: &Request{Context: Context{KeyRawRequest: dotted}}, pass: false},
+		{condition: &RequestPathCondition{Segment: segment(2), Matches: "other"}, r: &Request{Context: Context{KeyRawRequest: dotted}}, pass: true},
+		{condition: &RequestPathCondition{Segment: segment(-1), Matches: "12"}, r: &Request{Context: Context{KeyRawRequest: dotted}}, pass: true},
+		{condition: &RequestPathCondition{Matches: "/v1/tenants/other/documents/12"}, r: &Request{Context: Context{KeyRawRequest: dotted}}, pass: true},
+
+		{condition: &ClientCertificateCondition{Field: "common_name", Variable: "resource.tenant"}, pass: false},
+		{condition: &ClientCertificateCondition{Field: "common_name", Matches: `.*\.acme`}, pass: true},
+		{condition: &ClientCertificateCondition{Field: "organization"}, value: "acme", pass: true},
+		{condition: &ClientCertificateCondition{Field: "dns_names", Matches: `^billing\.internal$`}, pass: true},
+		{condition: &ClientCertificateCondition{Field: "email_addresses"}, value: "ops@acme", pass: true},
+		{condition: &ClientCertificateCondition{Field: "ip_addresses"}, value: "10.0.0.7", pass: true},
+		{condition: &ClientCertificateCondition{Field: "uris", Matches: "spiffe://acme/.*"}, pass: true},
+		{condition: &ClientCertificateCondition{Field: "organizational_unit", Matches: ".*"}, pass: false},
+		{condition: &ClientCertificateCondition{Field: "common_name", Matches: ".*"}, r: &Request{Context: Context{KeyRawRequest: unverified}}, pass: false},
+	} {
+		cr := c.r
+		if cr == nil {
+			cr = r
+		}
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, cr), "case %d", k)
+	}
+}
+
+func TestRequestConditionsValidate(t *testing.T) {
+	for k, c := range []struct {
+		condition ValidatableCondition
+		valid     bool
+	}{
+		{condition: &RequestHeaderCondition{Name: "X-Tenant"}, valid: true},
+		{condition: &RequestHeaderCondition{Matches: ".*"}, valid: false},
+		{condition: &RequestHeaderCondition{Name: "X-Tenant", Matches: "[a-z"}, valid: false},
+		{condition: &RequestQueryCondition{Name: "version", Matches: "^[0-9]+$"}, valid: true},
+		{condition: &RequestQueryCondition{}, valid: false},
+		{condition: &RequestMethodCondition{Methods: []string{"GET"}}, valid: true},
+		{condition: &RequestMethodCondition{}, valid: false},
+		{condition: &RequestPathCondition{Matches: "^/v1/"}, valid: true},
+		{condition: &RequestPathCondition{Matches: "(/v1"}, valid: false},
+		{condition: &ClientCertificateCondition{Field: "dns_names"}, valid: true},
+		{condition: &ClientCertificateCondition{Field: "serial"}, valid: false},
+	} {
+		assert.Equal(t, c.valid, c.condition.Validate() == nil, "case %d", k)
+	}
+}
@@ -0,0 +1,33 @@
+package ladon
+
+import (
+	"strings"
+)
+
+// StringInCondition is a condition which is fulfilled if the given
+// string value is one of the values specified in StringInCondition
+type StringInCondition struct {
+	Values     []string `json:"values"`
+	IgnoreCase bool     `json:"ignore_case,omitempty"`
+}
+
+// Fulfills returns true if the given value is a string and is one of
+// StringInCondition.Values, ignoring case if IgnoreCase is set
+func (c *StringInCondition) Fulfills(value interface{}, _ *Request) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	for _, v := range c.Values {
+		if s == v || (c.IgnoreCase && strings.EqualFold(s, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the condition's name.
+func (c *StringInCondition) GetName() string {
+	return "StringInCondition"
+}
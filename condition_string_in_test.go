@@ -0,0 +1,24 @@
+package ladon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringInCondition(t *testing.T) {
+	for k, c := range []struct {
+		condition *StringInCondition
+		value     interface{}
+		pass      bool
+	}{
+		{condition: &StringInCondition{Values: []string{"finance", "legal", "hr"}}, value: "legal", pass: true},
+		{condition: &StringInCondition{Values: []string{"finance", "legal", "hr"}}, value: "Legal", pass: false},
+		{condition: &StringInCondition{Values: []string{"finance", "legal", "hr"}, IgnoreCase: true}, value: "Legal", pass: true},
+		{condition: &StringInCondition{Values: []string{"finance", "legal", "hr"}, IgnoreCase: true}, value: "it", pass: false},
+		{condition: &StringInCondition{Values: []string{"1"}}, value: 1, pass: false},
+		{condition: &StringInCondition{}, value: "", pass: false},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, new(Request)), "case %d", k)
+	}
+}
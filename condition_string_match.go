@@ -2,6 +2,8 @@ package ladon
 
 import (
 	"regexp"
+
+	"github.com/pkg/errors"
 )
 
 // StringMatchCondition is a condition which is fulfilled if the given
@@ -12,12 +14,28 @@ type StringMatchCondition struct {
 
 // Fulfills returns true if the given value is a string and matches the regex
 // pattern in StringMatchCondition.Matches
-func (c *StringMatchCondition) Fulfills(value interface{}, _ *Request) bool {
+func (c *StringMatchCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the given value is a string and matches the regex
+// pattern in StringMatchCondition.Matches or an error if the pattern is invalid
+func (c *StringMatchCondition) Evaluate(value interface{}, _ *Request) (bool, error) {
+	reg, err := regexp.Compile(c.Matches)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
 	s, ok := value.(string)
 
-	matches, _ := regexp.MatchString(c.Matches, s)
+	return ok && reg.MatchString(s), nil
+}
 
-	return ok && matches
+// Validate returns an error if the pattern is invalid.
+func (c *StringMatchCondition) Validate() error {
+	_, err := regexp.Compile(c.Matches)
+	return errors.WithStack(err)
 }
 
 // GetName returns the condition's name.
@@ -1,10 +1,21 @@
 package ladon
 
-// EqualsSubjectCondition is a condition which is fulfilled if the request's subject is equal to the given value string
-type EqualsSubjectCondition struct{}
+// EqualsSubjectCondition is a condition which is fulfilled if the request's subject is equal to the given value string.
+// If Variable is set, the value of that variable (see Variables) is compared instead, e.g. "resource.1" for the
+// value captured by the first group of the policy's resource pattern.
+type EqualsSubjectCondition struct {
+	Variable string `json:"variable,omitempty"`
+}
 
 // Fulfills returns true if the request's subject is equal to the given value string
 func (c *EqualsSubjectCondition) Fulfills(value interface{}, r *Request) bool {
+	if c.Variable != "" {
+		var ok bool
+		if value, ok = r.Variables[c.Variable]; !ok {
+			return false
+		}
+	}
+
 	s, ok := value.(string)
 	if !ok {
 		return ok
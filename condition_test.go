@@ -2,7 +2,11 @@ package ladon
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,3 +67,122 @@ func TestUnmarshalFails(t *testing.T) {
 	}
 }`), &cs))
 }
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestConditionEvaluationErrors(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/documents", failingReader{})
+	req.Header.Set("Content-type", "application/json")
+	broken := &Request{Context: Context{KeyRawRequest: req}}
+
+	for k, c := range []struct {
+		condition ConditionWithError
+		value     interface{}
+		r         *Request
+	}{
+		{condition: &CIDRCondition{CIDR: "1"}, value: "192.168.1.67"},
+		{condition: &StringMatchCondition{Matches: "[a-z"}, value: "abc"},
+		{condition: &BodyMatchCondition{Path: ".a", Matches: "<[a-z>"}},
+		{condition: &BodyMatchCondition{Path: ".a", Matches: "<.*>"}, r: broken},
+		{condition: &BodyArrayMatchCondition{Mode: "some", Path: ".a", Matches: "<.*>"}},
+		{condition: &NumericCompareCondition{Operator: "less", Value: 1}, value: 0},
+		{condition: &NumericCompareCondition{Operator: "lt", Value: 1, Path: ".a"}, r: broken},
+		{condition: &ContextCompareCondition{Operator: "like", Key: "a"}, value: "a"},
+		{condition: &ContextCompareCondition{Operator: "eq", Path: ".a"}, value: "a", r: broken},
+		{condition: &TimeWindowCondition{Timezone: "Mars/Olympus_Mons"}},
+		{condition: &TimeWindowCondition{Days: []string{"someday"}}},
+		{condition: &TimeWindowCondition{Hours: []TimeRange{{From: "9am", To: "17:00"}}}},
+		{condition: &OrCondition{Conditions: Conditions{"ip": &CIDRCondition{CIDR: "1"}}}},
+		{condition: &NotCondition{Conditions: Conditions{"ip": &CIDRCondition{CIDR: "1"}}}},
+	} {
+		r := c.r
+		if r == nil {
+			r = &Request{Context: Context{}}
+		}
+		pass, err := c.condition.Evaluate(c.value, r)
+		assert.Error(t, err, "case %d", k)
+		assert.False(t, pass, "case %d", k)
+		assert.False(t, c.condition.Fulfills(c.value, r), "case %d", k)
+	}
+
+	pass, err := EvaluateCondition(&StringEqualCondition{Equals: "a"}, "a", new(Request))
+	assert.NoError(t, err)
+	assert.True(t, pass)
+}
+
+func TestLadonConditionError(t *testing.T) {
+	l := &Ladon{}
+	p := &DefaultPolicy{
+		ID:         "office",
+		Subjects:   []string{"peter"},
+		Actions:    []string{"get"},
+		Resources:  []string{"articles"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"remoteIP": &CIDRCondition{CIDR: "10.0.0.0/80"}},
+	}
+	r := &Request{Subjects: []string{"peter"}, Action: "get", Resource: "articles", Context: Context{"remoteIP": "10.0.0.1"}}
+
+	_, err := l.doPoliciesAllow(r, Policies{p})
+	require.Error(t, err)
+	ce, ok := errors.Cause(err).(*ConditionError)
+	require.True(t, ok, "%T", errors.Cause(err))
+	assert.Equal(t, "office", ce.Policy)
+	assert.Equal(t, "remoteIP", ce.Key)
+	assert.Equal(t, "CIDRCondition", ce.Condition)
+
+	_, err = l.explainPolicies(r, Policies{p})
+	assert.IsType(t, &ConditionError{}, errors.Cause(err))
+
+	p.Conditions["remoteIP"] = &CIDRCondition{CIDR: "10.0.0.0/8"}
+	_, err = l.doPoliciesAllow(r, Policies{p})
+	assert.NoError(t, err)
+}
+
+func TestConditionsValidate(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	for k, c := range []struct {
+		condition Condition
+		valid     bool
+	}{
+		{condition: &CIDRCondition{CIDR: "10.0.0.0/8"}, valid: true},
+		{condition: &CIDRCondition{CIDR: "1"}, valid: false},
+		{condition: &StringMatchCondition{Matches: "^[a-z]+$"}, valid: true},
+		{condition: &StringMatchCondition{Matches: "[a-z"}, valid: false},
+		{condition: &BodyMatchCondition{Path: ".a", Matches: "<[a-z]+>"}, valid: true},
+		{condition: &BodyMatchCondition{Path: ".a", Matches: "<[a-z>"}, valid: false},
+		{condition: &BodyArrayMatchCondition{Mode: Matchall, Path: ".a", Matches: "<.*>"}, valid: true},
+		{condition: &BodyArrayMatchCondition{Mode: "some", Path: ".a", Matches: "<.*>"}, valid: false},
+		{condition: &NumericCompareCondition{Operator: "between", Min: 1, Max: 2}, valid: true},
+		{condition: &NumericCompareCondition{Operator: "between", Min: 2, Max: 1}, valid: false},
+		{condition: &NumericCompareCondition{Operator: "less"}, valid: false},
+		{condition: &ContextCompareCondition{Operator: "eq", Key: "a"}, valid: true},
+		{condition: &ContextCompareCondition{Operator: "eq"}, valid: false},
+		{condition: &TimeWindowCondition{Days: []string{"mon"}, Hours: []TimeRange{{From: "09:00", To: "24:00"}}, Timezone: "Europe/Berlin"}, valid: true},
+		{condition: &TimeWindowCondition{Hours: []TimeRange{{From: "09:00", To: "5pm"}}}, valid: false},
+		{condition: &DateRangeCondition{NotBefore: &start, NotAfter: &end}, valid: true},
+		{condition: &DateRangeCondition{NotBefore: &end, NotAfter: &start}, valid: false},
+		{condition: &NotCondition{Conditions: Conditions{"ip": &CIDRCondition{CIDR: "1"}}}, valid: false},
+		{condition: &StringEqualCondition{}, valid: true},
+	} {
+		err := Conditions{"key": c.condition}.Validate()
+		if c.valid {
+			assert.NoError(t, err, "case %d", k)
+			continue
+		}
+		require.Error(t, err, "case %d", k)
+		assert.Contains(t, err.Error(), "invalid condition key ("+c.condition.GetName()+")", "case %d", k)
+	}
+
+	p := &DefaultPolicy{
+		Subjects:   []string{"peter"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"remoteIP": &CIDRCondition{CIDR: "1"}},
+	}
+	assert.EqualError(t, p.Validate(), "invalid condition remoteIP (CIDRCondition): invalid CIDR address: 1")
+}
@@ -0,0 +1,226 @@
+package ladon
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeRange is a range of the day, e.g. from "09:00" to "17:30". From is inclusive and To exclusive. If To is
+// before From, the range spans midnight.
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TimeWindowCondition is a condition which is fulfilled if the time falls on one of the days of the week and
+// within one of the hour ranges, both evaluated in the IANA timezone, e.g. "Europe/Berlin". Days are given as
+// English names or their first three letters. No days means every day, no hours means all day and no timezone
+// means UTC.
+//
+// The time is the context value, which may be a time.Time, an RFC 3339 string or a unix timestamp in seconds. If
+// the request's context has no value, the time the request is evaluated at is used (see Ladon.Clock).
+type TimeWindowCondition struct {
+	Days     []string    `json:"days,omitempty"`
+	Hours    []TimeRange `json:"hours,omitempty"`
+	Timezone string      `json:"timezone,omitempty"`
+}
+
+// Fulfills returns true if the time is within the window.
+func (c *TimeWindowCondition) Fulfills(value interface{}, r *Request) bool {
+	pass, _ := c.Evaluate(value, r)
+	return pass
+}
+
+// Evaluate returns true if the time is within the window or an error if a day, an hour range or the timezone
+// is invalid.
+func (c *TimeWindowCondition) Evaluate(value interface{}, r *Request) (bool, error) {
+	loc, err := loadLocation(c.Timezone)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	t, ok := conditionTime(value, r)
+	if !ok {
+		return false, nil
+	}
+	t = t.In(loc)
+
+	if len(c.Days) > 0 {
+		var found bool
+		for _, day := range c.Days {
+			d, ok := parseWeekday(day)
+			if !ok {
+				return false, errors.Errorf("invalid day %q", day)
+			}
+			found = found || d == t.Weekday()
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if len(c.Hours) == 0 {
+		return true, nil
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	var found bool
+	for _, h := range c.Hours {
+		from, ok := parseClock(h.From)
+		if !ok {
+			return false, errors.Errorf("invalid time %q", h.From)
+		}
+		to, ok := parseClock(h.To)
+		if !ok {
+			return false, errors.Errorf("invalid time %q", h.To)
+		}
+
+		if from <= to && minute >= from && minute < to {
+			found = true
+		} else if from > to && (minute >= from || minute < to) {
+			found = true
+		}
+	}
+	return found, nil
+}
+
+// Validate returns an error if a day, an hour range or the timezone is invalid.
+func (c *TimeWindowCondition) Validate() error {
+	if _, err := loadLocation(c.Timezone); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, day := range c.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return errors.Errorf("invalid day %q", day)
+		}
+	}
+	for _, h := range c.Hours {
+		for _, clock := range []string{h.From, h.To} {
+			if _, ok := parseClock(clock); !ok {
+				return errors.Errorf("invalid time %q", clock)
+			}
+		}
+	}
+	return nil
+}
+
+// GetName returns the condition's name.
+func (c *TimeWindowCondition) GetName() string {
+	return "TimeWindowCondition"
+}
+
+// DateRangeCondition is a condition which is fulfilled if the time is neither before NotBefore nor after
+// NotAfter. Both bounds are inclusive and optional. The time is taken like TimeWindowCondition does.
+type DateRangeCondition struct {
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+}
+
+// Fulfills returns true if the time is within the range.
+func (c *DateRangeCondition) Fulfills(value interface{}, r *Request) bool {
+	t, ok := conditionTime(value, r)
+	if !ok {
+		return false
+	}
+	if c.NotBefore != nil && t.Before(*c.NotBefore) {
+		return false
+	}
+	if c.NotAfter != nil && t.After(*c.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Validate returns an error if NotBefore is after NotAfter.
+func (c *DateRangeCondition) Validate() error {
+	if c.NotBefore != nil && c.NotAfter != nil && c.NotBefore.After(*c.NotAfter) {
+		return errors.Errorf("not_before %s is after not_after %s", c.NotBefore.Format(time.RFC3339), c.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// GetName returns the condition's name.
+func (c *DateRangeCondition) GetName() string {
+	return "DateRangeCondition"
+}
+
+// conditionTime returns the time given as context value or, if there is none, the time the request is
+// evaluated at.
+func conditionTime(value interface{}, r *Request) (time.Time, bool) {
+	switch v := value.(type) {
+	case nil:
+		if r.Time.IsZero() {
+			return time.Now(), true
+		}
+		return r.Time, true
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	default:
+		if n, ok := toFloat64(value); ok {
+			sec := int64(n)
+			return time.Unix(sec, int64((n-float64(sec))*1e9)), true
+		}
+		return time.Time{}, false
+	}
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(day)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if day == name || day == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock returns the minutes since midnight of a "15:04" formatted time.
+func parseClock(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		// 24:00 is a valid end of the day.
+		if clock == "24:00" {
+			return 24 * 60, true
+		}
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+var locations = struct {
+	sync.RWMutex
+	m map[string]*time.Location
+}{m: map[string]*time.Location{}}
+
+// loadLocation returns the location with the IANA name. Locations are cached, because loading them reads
+// the timezone database.
+func loadLocation(name string) (*time.Location, error) {
+	locations.RLock()
+	loc, ok := locations.m[name]
+	locations.RUnlock()
+	if ok {
+		return loc, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	locations.Lock()
+	locations.m[name] = loc
+	locations.Unlock()
+	return loc, nil
+}
@@ -0,0 +1,107 @@
+package ladon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeWindowCondition(t *testing.T) {
+	// 2017-06-05 is a Monday.
+	monday := time.Date(2017, 6, 5, 10, 30, 0, 0, time.UTC)
+	businessHours := &TimeWindowCondition{
+		Days:     []string{"mon", "Tuesday", "wed", "thu", "fri"},
+		Hours:    []TimeRange{{From: "09:00", To: "17:00"}},
+		Timezone: "Europe/Berlin",
+	}
+	nightShift := &TimeWindowCondition{Hours: []TimeRange{{From: "22:00", To: "06:00"}}}
+
+	for k, c := range []struct {
+		condition *TimeWindowCondition
+		value     interface{}
+		now       time.Time
+		pass      bool
+	}{
+		{condition: businessHours, now: monday, pass: true},
+		// 16:30 UTC is 18:30 in Berlin.
+		{condition: businessHours, now: monday.Add(6 * time.Hour), pass: false},
+		// 07:30 UTC is 09:30 in Berlin.
+		{condition: businessHours, now: monday.Add(-3 * time.Hour), pass: true},
+		{condition: businessHours, now: monday.AddDate(0, 0, -1), pass: false},
+		{condition: businessHours, value: "2017-06-10T10:00:00+02:00", now: monday, pass: false},
+		{condition: businessHours, value: "2017-06-09T10:00:00+02:00", now: monday, pass: true},
+		{condition: businessHours, value: float64(monday.Unix()), pass: true},
+		{condition: businessHours, value: monday, pass: true},
+		{condition: businessHours, value: "yesterday", now: monday, pass: false},
+		{condition: nightShift, now: monday, pass: false},
+		{condition: nightShift, now: monday.Add(12 * time.Hour), pass: true},
+		{condition: nightShift, now: monday.Add(-5 * time.Hour), pass: true},
+		{condition: &TimeWindowCondition{Days: []string{"sun"}}, now: monday.AddDate(0, 0, -1), pass: true},
+		{condition: &TimeWindowCondition{Timezone: "Mars/Olympus_Mons"}, now: monday, pass: false},
+		{condition: &TimeWindowCondition{}, now: monday, pass: true},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, &Request{Time: c.now}), "case %d", k)
+	}
+}
+
+func TestDateRangeCondition(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2017, 12, 31, 23, 59, 59, 0, time.UTC)
+	contract := &DateRangeCondition{NotBefore: &start, NotAfter: &end}
+
+	for k, c := range []struct {
+		condition *DateRangeCondition
+		value     interface{}
+		now       time.Time
+		pass      bool
+	}{
+		{condition: contract, now: start, pass: true},
+		{condition: contract, now: end, pass: true},
+		{condition: contract, now: start.Add(-time.Second), pass: false},
+		{condition: contract, now: end.Add(time.Second), pass: false},
+		{condition: contract, value: "2017-06-01T00:00:00Z", pass: true},
+		{condition: contract, value: "2018-06-01T00:00:00Z", now: start, pass: false},
+		{condition: &DateRangeCondition{NotAfter: &end}, now: start.AddDate(-10, 0, 0), pass: true},
+		{condition: &DateRangeCondition{}, now: start, pass: true},
+	} {
+		assert.Equal(t, c.pass, c.condition.Fulfills(c.value, &Request{Time: c.now}), "case %d", k)
+	}
+
+	out, err := json.Marshal(Conditions{"contract": contract})
+	require.NoError(t, err)
+	cs := Conditions{}
+	require.NoError(t, json.Unmarshal(out, &cs))
+	assert.Equal(t, contract.NotAfter.Unix(), cs["contract"].(*DateRangeCondition).NotAfter.Unix())
+}
+
+func TestLadonClock(t *testing.T) {
+	now := time.Date(2017, 6, 5, 10, 30, 0, 0, time.UTC)
+	l := &Ladon{Clock: func() time.Time { return now }}
+	p := &DefaultPolicy{
+		ID:        "business-hours",
+		Subjects:  []string{"peter"},
+		Actions:   []string{"get"},
+		Resources: []string{"articles"},
+		Effect:    AllowAccess,
+		Conditions: Conditions{
+			"now": &TimeWindowCondition{Hours: []TimeRange{{From: "09:00", To: "17:00"}}},
+		},
+	}
+	r := &Request{Subjects: []string{"peter"}, Action: "get", Resource: "articles"}
+
+	_, err := l.doPoliciesAllow(r, Policies{p})
+	assert.NoError(t, err)
+	assert.True(t, r.Time.IsZero())
+
+	now = now.Add(8 * time.Hour)
+	_, err = l.doPoliciesAllow(r, Policies{p})
+	assert.Error(t, err)
+
+	// The request's own time takes precedence over the clock.
+	r.Time = now.Add(-8 * time.Hour)
+	_, err = l.doPoliciesAllow(r, Policies{p})
+	assert.NoError(t, err)
+}
@@ -5,3 +5,10 @@ const AllowAccess = "allow"
 
 // DenyAccess should be used as effect for policies that deny access.
 const DenyAccess = "deny"
+
+// SyntaxRegexp should be used as syntax for policies whose patterns contain regular expressions
+// enclosed in delimiters. It is the default syntax.
+const SyntaxRegexp = "regexp"
+
+// SyntaxGlob should be used as syntax for policies whose patterns are globs, see GlobMatcher.
+const SyntaxGlob = "glob"
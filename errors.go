@@ -1,6 +1,7 @@
 package ladon
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -31,6 +32,30 @@ var (
 	})
 )
 
+// ConditionError is returned when a condition of a policy could not be evaluated, e.g. because it is misconfigured.
+type ConditionError struct {
+	// Policy is the ID of the policy.
+	Policy string
+
+	// Key is the context key of the condition.
+	Key string
+
+	// Condition is the condition's name.
+	Condition string
+
+	// Err is the error returned by the condition.
+	Err error
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("Condition %s (%s) of policy %s could not be evaluated: %s", e.Key, e.Condition, e.Policy, e.Err)
+}
+
+// Unwrap returns the error returned by the condition.
+func (e *ConditionError) Unwrap() error {
+	return e.Err
+}
+
 func NewErrResourceNotFound(err error) error {
 	if err == nil {
 		err = errors.New("not found")
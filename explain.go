@@ -0,0 +1,156 @@
+package ladon
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Decision explains how a request was decided.
+type Decision struct {
+	// Allowed is true if the request was granted.
+	Allowed bool `json:"allowed"`
+
+	// Policy is the ID of the policy that decided the request. It is empty if the request
+	// was denied because no policy matched.
+	Policy string `json:"policy"`
+
+	// Policies holds the evaluation result of every candidate policy.
+	Policies []*PolicyDecision `json:"policies"`
+}
+
+// PolicyDecision describes which parts of a policy matched a request.
+type PolicyDecision struct {
+	// ID is the policy's id.
+	ID string `json:"id"`
+
+	// Effect is the policy's effect.
+	Effect string `json:"effect"`
+
+	// Actions is true if the request's action matched one of the policy's actions.
+	Actions bool `json:"actions"`
+
+	// Subjects is true if one of the request's subjects matched one of the policy's subjects.
+	Subjects bool `json:"subjects"`
+
+	// Resources is true if the request's resource matched one of the policy's resources.
+	Resources bool `json:"resources"`
+
+	// Conditions holds the result of every named condition of the policy.
+	Conditions map[string]bool `json:"conditions"`
+
+	// Errors holds the error of every named condition which could not be evaluated. Such conditions are not
+	// fulfilled.
+	Errors map[string]string `json:"errors,omitempty"`
+
+	// conditionError is the error IsAllowed returns when it evaluates the conditions of the policy.
+	conditionError error
+}
+
+// Applies returns true if the policy matched the request and all of its conditions are fulfilled.
+func (d *PolicyDecision) Applies() bool {
+	if !d.Actions || !d.Subjects || !d.Resources {
+		return false
+	}
+	for _, pass := range d.Conditions {
+		if !pass {
+			return false
+		}
+	}
+	return true
+}
+
+// Explain evaluates the request like IsAllowed does, but instead of stopping at the first mismatch it
+// reports for every candidate policy which of its actions, subjects, resources and conditions matched.
+// Conditions which can not be evaluated are reported in the PolicyDecision. A ConditionError is only
+// returned if IsAllowed returns it as well, i.e. if the policy matches, the broken condition is the first of
+// its conditions in order of their keys which is not fulfilled and no deny policy applied before.
+func (l *Ladon) Explain(r *Request) (*Decision, error) {
+	policies, err := l.Manager.FindRequestCandidates(r)
+	if err != nil {
+		return nil, err
+	}
+	return l.explainPolicies(r, policies)
+}
+
+func (l *Ladon) explainPolicies(r *Request, policies []Policy) (*Decision, error) {
+	d := &Decision{Policies: make([]*PolicyDecision, 0, len(policies))}
+	r = l.prepare(r)
+
+	var allowedBy string
+	var deniedBy string
+	for _, p := range policies {
+		pd, err := l.explainPolicy(p, r)
+		if err != nil {
+			return nil, err
+		}
+		d.Policies = append(d.Policies, pd)
+
+		// IsAllowed stops at the first deny policy which applies, so it never evaluates the later ones.
+		if cerr := pd.conditionError; cerr != nil && deniedBy == "" {
+			return nil, cerr
+		}
+
+		if !pd.Applies() {
+			continue
+		}
+
+		// A deny overrides all allow policies, so the first applying deny policy decides the request.
+		if !p.AllowAccess() {
+			if deniedBy == "" {
+				deniedBy = p.GetID()
+			}
+		} else if allowedBy == "" {
+			allowedBy = p.GetID()
+		}
+	}
+
+	if deniedBy != "" {
+		d.Policy = deniedBy
+	} else if allowedBy != "" {
+		d.Allowed = true
+		d.Policy = allowedBy
+	}
+	return d, nil
+}
+
+func (l *Ladon) explainPolicy(p Policy, r *Request) (*PolicyDecision, error) {
+	pd := &PolicyDecision{
+		ID:         p.GetID(),
+		Effect:     p.GetEffect(),
+		Conditions: map[string]bool{},
+	}
+
+	var err error
+	if pd.Actions, err = l.matcher().Matches(p, ResolveVariables(p, p.GetActions(), r), r.Action); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if pd.Subjects, err = l.checkSubjects(p, r); err != nil {
+		return nil, err
+	}
+	if pd.Resources, err = l.matcher().Matches(p, ResolveVariables(p, p.GetResources(), r), r.Resource); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vr, err := l.withVariables(p, r)
+	if err != nil {
+		return nil, err
+	}
+	// IsAllowed only evaluates the conditions of policies which match otherwise and stops at the first
+	// condition which is not fulfilled.
+	decisive := pd.Actions && pd.Subjects && pd.Resources
+	conditions := p.GetConditions()
+	for _, key := range conditions.keys() {
+		pd.Conditions[key], err = l.evaluateCondition(p, key, conditions[key], vr)
+		if err != nil {
+			if pd.Errors == nil {
+				pd.Errors = map[string]string{}
+			}
+			pd.Errors[key] = err.Error()
+			if decisive {
+				pd.conditionError = err
+			}
+		}
+		if !pd.Conditions[key] {
+			decisive = false
+		}
+	}
+	return pd, nil
+}
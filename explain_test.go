@@ -0,0 +1,152 @@
+package ladon_test
+
+import (
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLadonExplain(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range pols {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	for k, c := range cases {
+		d, err := warden.Explain(c.accessRequest)
+		require.NoError(t, err)
+		assert.Equal(t, !c.expectErr, d.Allowed, "case %d: %s", k, c.description)
+	}
+
+	d, err := warden.Explain(&Request{
+		Subjects: []string{"peter"},
+		Action:   "delete",
+		Resource: "myrn:some.domain.com:resource:123",
+		Context: Context{
+			"owner":    "zac",
+			"clientIP": "127.0.0.1",
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, "", d.Policy)
+	for _, pd := range d.Policies {
+		if pd.ID != "1" {
+			continue
+		}
+		assert.True(t, pd.Actions)
+		assert.True(t, pd.Subjects)
+		assert.True(t, pd.Resources)
+		assert.Equal(t, map[string]bool{"owner": false, "clientIP": true}, pd.Conditions)
+		assert.False(t, pd.Applies())
+	}
+
+	d, err = warden.Explain(&Request{
+		Subjects: []string{"max"},
+		Action:   "broadcast",
+		Resource: "myrn:some.domain.com:resource:123",
+	})
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, "3", d.Policy)
+}
+
+// failingCondition is a condition which can never be evaluated.
+type failingCondition struct{}
+
+func (failingCondition) Fulfills(interface{}, *Request) bool { return false }
+
+func (failingCondition) Evaluate(interface{}, *Request) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func (failingCondition) GetName() string { return "failingCondition" }
+
+// orderedManager returns its policies in order, as managers backed by a database do.
+type orderedManager struct {
+	Manager
+	policies Policies
+}
+
+func (m *orderedManager) FindRequestCandidates(*Request) (Policies, error) { return m.policies, nil }
+
+func TestLadonExplainConditionErrors(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range []Policy{
+		&DefaultPolicy{
+			ID:        "1",
+			Subjects:  []string{"peter"},
+			Actions:   []string{"get"},
+			Resources: []string{"articles"},
+			Effect:    AllowAccess,
+		},
+		&DefaultPolicy{
+			ID:         "2",
+			Subjects:   []string{"peter"},
+			Actions:    []string{"delete"},
+			Resources:  []string{"articles"},
+			Effect:     AllowAccess,
+			Conditions: Conditions{"b": failingCondition{}},
+		},
+	} {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	r := &Request{Subjects: []string{"peter"}, Action: "get", Resource: "articles", Context: Context{"b": "10.0.0.1"}}
+	require.NoError(t, warden.IsAllowed(r))
+
+	// The condition of policy 2 is broken, but policy 2 does not match the action.
+	d, err := warden.Explain(r)
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, "1", d.Policy)
+	for _, pd := range d.Policies {
+		if pd.ID == "2" {
+			assert.False(t, pd.Conditions["b"])
+			assert.Contains(t, pd.Errors["b"], "could not be evaluated")
+		}
+	}
+
+	// If policy 2 matches, the error is returned like IsAllowed does.
+	r.Action = "delete"
+	assert.Error(t, warden.IsAllowed(r))
+	_, err = warden.Explain(r)
+	require.Error(t, err)
+
+	// IsAllowed stops at the first condition which is not fulfilled, so the broken condition is not evaluated.
+	require.Nil(t, warden.Manager.Create(&DefaultPolicy{
+		ID:         "3",
+		Subjects:   []string{"peter"},
+		Actions:    []string{"update"},
+		Resources:  []string{"articles"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"a": &StringEqualCondition{Equals: "yes"}, "b": failingCondition{}},
+	}))
+	r.Action = "update"
+	assert.Equal(t, errors.Cause(ErrRequestDenied), errors.Cause(warden.IsAllowed(r)))
+	d, err = warden.Explain(r)
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	for _, pd := range d.Policies {
+		if pd.ID == "3" {
+			assert.Equal(t, map[string]bool{"a": false, "b": false}, pd.Conditions)
+			assert.Contains(t, pd.Errors["b"], "could not be evaluated")
+		}
+	}
+
+	// IsAllowed stops at the first deny policy which applies, so the broken condition is not evaluated.
+	warden = &Ladon{Manager: &orderedManager{policies: Policies{
+		&DefaultPolicy{ID: "1", Subjects: []string{"peter"}, Actions: []string{"delete"}, Resources: []string{"articles"}, Effect: DenyAccess},
+		&DefaultPolicy{ID: "2", Subjects: []string{"peter"}, Actions: []string{"delete"}, Resources: []string{"articles"}, Effect: AllowAccess, Conditions: Conditions{"b": failingCondition{}}},
+	}}}
+	r.Action = "delete"
+	assert.Equal(t, errors.Cause(ErrRequestForcefullyDenied), errors.Cause(warden.IsAllowed(r)))
+	d, err = warden.Explain(r)
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, "1", d.Policy)
+}
@@ -0,0 +1,53 @@
+package ladon
+
+// FilterAllowed returns the resources on which one of the subjects may perform the action, in input order.
+func (l *Ladon) FilterAllowed(subjects []string, action string, resources []string, ctx Context) ([]string, error) {
+	allowed, _, err := l.FilterAllowedWithReasons(subjects, action, resources, ctx)
+	return allowed, err
+}
+
+// FilterAllowedWithReasons works like FilterAllowed, but additionally returns the reason each denied resource
+// was denied for. If the manager implements BatchManager, the candidates are fetched only once.
+func (l *Ladon) FilterAllowedWithReasons(subjects []string, action string, resources []string, ctx Context) ([]string, map[string]error, error) {
+	var candidates Policies
+	bm, batch := l.Manager.(BatchManager)
+	if batch {
+		var err error
+		if candidates, err = bm.FindSubjectCandidates(subjects); err != nil {
+			l.logger().Error("could not find subject candidates", "error", err)
+			return nil, nil, err
+		}
+	}
+
+	// The resources are evaluated at the same time and share the decoded body of the raw http request.
+	prepared := l.prepare(&Request{
+		Subjects: subjects,
+		Action:   action,
+		Context:  ctx,
+	})
+
+	allowed := make([]string, 0, len(resources))
+	denied := map[string]error{}
+	for _, resource := range resources {
+		rr := *prepared
+		rr.Resource = resource
+		r := &rr
+
+		policies := candidates
+		if !batch {
+			var err error
+			if policies, err = l.Manager.FindRequestCandidates(r); err != nil {
+				l.logger().Error("could not find request candidates", "error", err)
+				return nil, nil, err
+			}
+		}
+
+		if err := l.isAllowed(r, policies); err != nil {
+			denied[resource] = err
+			continue
+		}
+		allowed = append(allowed, resource)
+	}
+
+	return allowed, denied, nil
+}
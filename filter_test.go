@@ -0,0 +1,88 @@
+package ladon_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLadonFilterAllowed(t *testing.T) {
+	m := NewMemoryManager()
+	for _, pol := range pols {
+		require.Nil(t, m.Create(pol))
+	}
+
+	resources := []string{
+		"myrn:something:foo:3",
+		"myrn:some.domain.com:resource:999",
+		"myrn:some.domain.com:resource:123",
+		"myrn:something:foo:1",
+	}
+	ctx := Context{"owner": "peter", "clientIP": "127.0.0.1"}
+
+	for _, warden := range []*Ladon{{Manager: m}, {Manager: &plainManager{m}}} {
+		allowed, err := warden.FilterAllowed([]string{"peter"}, "delete", resources, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"myrn:something:foo:3", "myrn:some.domain.com:resource:123", "myrn:something:foo:1"}, allowed)
+
+		allowed, denied, err := warden.FilterAllowedWithReasons([]string{"max"}, "broadcast", resources[:2], nil)
+		require.NoError(t, err)
+		assert.Empty(t, allowed)
+		require.Len(t, denied, 2)
+		assert.Equal(t, errors.Cause(ErrRequestForcefullyDenied), errors.Cause(denied[resources[0]]))
+	}
+}
+
+// readCounter counts the reads of a request body.
+type readCounter struct {
+	*strings.Reader
+	reads int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	c.reads++
+	return c.Reader.Read(p)
+}
+
+func TestLadonFilterAllowedPreparesOnce(t *testing.T) {
+	now := time.Date(2017, 6, 5, 10, 30, 0, 0, time.UTC)
+	audit := NewMemoryAuditLogger()
+	warden := &Ladon{Manager: NewMemoryManager(), AuditLogger: audit, Clock: func() time.Time { return now }}
+	require.NoError(t, warden.Manager.Create(&DefaultPolicy{
+		ID:         "1",
+		Subjects:   []string{"peter"},
+		Actions:    []string{"create"},
+		Resources:  []string{"orders:<.*>"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"owner": &BodyMatchCondition{Path: ".owner", Matches: "peter"}},
+	}))
+
+	// reads returns how often the body is read when the resources are filtered.
+	reads := func(resources []string) int {
+		body := &readCounter{Reader: strings.NewReader(`{"owner":"peter"}`)}
+		req, _ := http.NewRequest("POST", "http://fuac.xxx.xxx.xxx/v1/orders", body)
+		req.Header.Set("Content-type", "application/json")
+
+		allowed, err := warden.FilterAllowed([]string{"peter"}, "create", resources, Context{KeyRawRequest: req})
+		require.NoError(t, err)
+		assert.Equal(t, resources, allowed)
+		return body.reads
+	}
+
+	once := reads([]string{"orders:1"})
+	assert.True(t, once > 0)
+	assert.Equal(t, once, reads([]string{"orders:1", "orders:2", "orders:3"}))
+
+	entries := audit.Entries()
+	require.Len(t, entries, 4)
+	for _, e := range entries {
+		assert.True(t, now.Equal(e.Time), "%s", e.Time)
+	}
+}
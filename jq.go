@@ -5,18 +5,99 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/golang-lru"
 )
 
+// Query is a compiled json query. It supports a subset of jq:
+//
+//	.a.b, ."a.b", .["a.b"]   object fields, also with dots or other special characters in the key
+//	.[0], .[-1]              array elements, counting from the end if negative
+//	.[1:3], .[:-1]           array slices
+//	.[], .[*], .*            all elements of an array or values of an object
+//	..                       the value and all of its descendants, so ..name are all fields called name
+//	a | b                    b applied to every result of a
+//	select(cond)             the value if cond holds, e.g. select(.type == "x" and .size >= 10)
+//
+// Conditions compare paths and literals (strings, numbers, true, false and null) with ==, !=, <, <=, > and >=, and
+// combine them with and, or and parentheses. A path in a condition holds if one of its results holds, a missing
+// value is null. For compatibility with JSONPath, a query may start with $, [?(cond)] filters elements and @
+// refers to the current value. The leading dot may be omitted.
+//
+// Queries are parsed once and evaluated on decoded json values, i.e. the result of json.Unmarshal into an
+// interface{}.
+type Query struct {
+	query    string
+	steps    []step
+	singular bool
+}
+
+// CompileQuery parses a json query.
+func CompileQuery(query string) (*Query, error) {
+	p := &queryParser{query: query}
+	q, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %s", query, err)
+	}
+	return q, nil
+}
+
+// String returns the query as it was compiled.
+func (q *Query) String() string {
+	return q.query
+}
+
+// Eval returns all results of the query applied to v.
+func (q *Query) Eval(v interface{}) []interface{} {
+	results := []interface{}{}
+	evalSteps(q.steps, v, func(r interface{}) {
+		results = append(results, r)
+	})
+	return results
+}
+
+// Get returns the result of the query applied to v. If the query addresses a single value, like .a[0], the
+// value is returned. If the query can produce several values, like .a[], all of them are returned as
+// []interface{}. It returns false if there is no result, so a condition never holds vacuously for a missing
+// value.
+func (q *Query) Get(v interface{}) (interface{}, bool) {
+	results := q.Eval(v)
+	if len(results) == 0 {
+		return nil, false
+	}
+	if !q.singular {
+		return results, true
+	}
+	return results[0], true
+}
+
+// queries caches compiled queries, because conditions store their queries as strings.
+var queries, _ = lru.New(512)
+
+// cachedQuery returns the compiled query, compiling it if it is not cached.
+func cachedQuery(query string) (*Query, error) {
+	if q, ok := queries.Get(query); ok {
+		return q.(*Query), nil
+	}
+	q, err := CompileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	queries.Add(query, q)
+	return q, nil
+}
+
+// JsonQuery applies the query (see Query) to the json document and returns the result as json.
 func JsonQuery(data []byte, query string) ([]byte, error) {
-	if len(query) > 0 && query[0] == '.' {
-		if len(query) == 1 {
-			return data, nil
-		}
-		query = strings.Replace(query, "[", ".[", -1)
+	q, err := cachedQuery(query)
+	if err != nil {
+		return nil, err
 	}
-	elems := strings.Split(strings.Trim(query, "."), ".")
+
 	var reply interface{}
 	if err := json.Unmarshal(data, &reply); err != nil {
 		return nil, err
@@ -24,43 +105,12 @@ func JsonQuery(data []byte, query string) ([]byte, error) {
 	if reply == nil {
 		return nil, errors.New("query is null")
 	}
-	switch {
-	case reflect.TypeOf(reply).Kind() == reflect.Map && !strings.Contains(elems[0], "["):
-		replyMap := reply.(map[string]interface{})
-		if child, ok := replyMap[elems[0]]; ok {
-			if len(elems) > 1 {
-				childjson, _ := json.Marshal(child)
-				return JsonQuery(childjson, strings.Join(elems[1:], "."))
-			} else {
-				return json.Marshal(child)
-			}
-		} else {
-			return nil, errors.New("No such element:" + elems[0])
-		}
-	case reflect.TypeOf(reply).Kind() == reflect.Slice && strings.Contains(elems[0], "["):
-		if key, err := strconv.Atoi(strings.Trim(elems[0], "[]")); err != nil {
-			return nil, err
-		} else {
-			replySlice := reply.([]interface{})
-			if key > len(replySlice)-1 {
-				return nil, errors.New("slice out of range")
-			}
-			if len(elems) > 1 {
-				childjson, _ := json.Marshal(replySlice[key])
-				return JsonQuery(childjson, strings.Join(elems[1:], "."))
-			} else {
-				return json.Marshal(replySlice[key])
-			}
-		}
-	case reflect.TypeOf(reply).Kind() == reflect.String || reflect.TypeOf(reply).Kind() == reflect.Float64:
-		if len(elems) > 0 {
-			return nil, errors.New("string or number type has no child field!")
-		} else {
-			return data, nil
-		}
-	default:
-		return nil, errors.New("Invalid json type:" + reflect.TypeOf(reply).Kind().String() + " with query: " + query)
+
+	v, ok := q.Get(reply)
+	if !ok {
+		return nil, errors.New("No such element: " + query)
 	}
+	return json.Marshal(v)
 }
 
 func String(data []byte, err error) (string, error) {
@@ -71,17 +121,29 @@ func String(data []byte, err error) (string, error) {
 	if err := json.Unmarshal(data, &tmp); err != nil {
 		return "", err
 	}
-	switch tmp.(type) {
+	return jsonString(tmp)
+}
+
+// jsonString converts a decoded json value to a string like String does.
+func jsonString(v interface{}) (string, error) {
+	switch t := v.(type) {
 	case string:
-		return strings.Trim(tmp.(string), "\""), nil
+		return strings.Trim(t, "\""), nil
 	case float64:
-		return fmt.Sprintf("%f", tmp.(float64)), nil
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
 	case map[string]interface{}, []interface{}:
-		return string(data), nil
+		data, err := json.Marshal(t)
+		return string(data), err
 	case nil:
 		return "<nil>", nil
 	default:
-		return "", errors.New("unknown json format:" + string(data))
+		// Numbers decoded from other formats than json, e.g. yaml integers.
+		if n, ok := toFloat64(v); ok {
+			return strconv.FormatFloat(n, 'f', -1, 64), nil
+		}
+		return "", fmt.Errorf("unknown json format: %v", v)
 	}
 }
 
@@ -107,3 +169,591 @@ func Bool(data []byte, err error) (bool, error) {
 		return ret, nil
 	}
 }
+
+// A step maps every value to any number of results.
+type step interface {
+	eval(v interface{}, emit func(interface{}))
+}
+
+func evalSteps(steps []step, v interface{}, emit func(interface{})) {
+	if len(steps) == 0 {
+		emit(v)
+		return
+	}
+	steps[0].eval(v, func(r interface{}) {
+		evalSteps(steps[1:], r, emit)
+	})
+}
+
+type fieldStep string
+
+func (s fieldStep) eval(v interface{}, emit func(interface{})) {
+	if m, ok := v.(map[string]interface{}); ok {
+		if child, ok := m[string(s)]; ok {
+			emit(child)
+		}
+	}
+}
+
+type indexStep int
+
+func (s indexStep) eval(v interface{}, emit func(interface{})) {
+	a, ok := v.([]interface{})
+	if !ok {
+		return
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(a)
+	}
+	if i >= 0 && i < len(a) {
+		emit(a[i])
+	}
+}
+
+type sliceStep struct {
+	from, to       int
+	hasFrom, hasTo bool
+}
+
+func (s sliceStep) eval(v interface{}, emit func(interface{})) {
+	a, ok := v.([]interface{})
+	if !ok {
+		return
+	}
+	bound := func(i int, set bool, def int) int {
+		if !set {
+			return def
+		}
+		if i < 0 {
+			i += len(a)
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > len(a) {
+			return len(a)
+		}
+		return i
+	}
+	from, to := bound(s.from, s.hasFrom, 0), bound(s.to, s.hasTo, len(a))
+	if to < from {
+		to = from
+	}
+	emit(a[from:to])
+}
+
+type iterateStep struct{}
+
+func (iterateStep) eval(v interface{}, emit func(interface{})) {
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			emit(e)
+		}
+	case map[string]interface{}:
+		for _, k := range sortedKeys(t) {
+			emit(t[k])
+		}
+	}
+}
+
+type recurseStep struct{}
+
+func (s recurseStep) eval(v interface{}, emit func(interface{})) {
+	emit(v)
+	iterateStep{}.eval(v, func(child interface{}) {
+		s.eval(child, emit)
+	})
+}
+
+type selectStep struct {
+	cond expr
+}
+
+func (s selectStep) eval(v interface{}, emit func(interface{})) {
+	if s.cond.truthy(v) {
+		emit(v)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// An expr is part of a select condition.
+type expr interface {
+	// values returns the values of the expression for the current value v.
+	values(v interface{}) []interface{}
+
+	// truthy returns true if the expression holds for v.
+	truthy(v interface{}) bool
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e literalExpr) values(interface{}) []interface{} {
+	return []interface{}{e.value}
+}
+
+func (e literalExpr) truthy(interface{}) bool {
+	return isTruthy(e.value)
+}
+
+type pathExpr struct {
+	steps []step
+}
+
+// values returns the results of the path. Like in jq, a missing value is null.
+func (e pathExpr) values(v interface{}) []interface{} {
+	var results []interface{}
+	evalSteps(e.steps, v, func(r interface{}) {
+		results = append(results, r)
+	})
+	if len(results) == 0 {
+		return []interface{}{nil}
+	}
+	return results
+}
+
+func (e pathExpr) truthy(v interface{}) bool {
+	for _, r := range e.values(v) {
+		if isTruthy(r) {
+			return true
+		}
+	}
+	return false
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+func (e logicalExpr) values(v interface{}) []interface{} {
+	return []interface{}{e.truthy(v)}
+}
+
+func (e logicalExpr) truthy(v interface{}) bool {
+	if e.and {
+		return e.left.truthy(v) && e.right.truthy(v)
+	}
+	return e.left.truthy(v) || e.right.truthy(v)
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e compareExpr) values(v interface{}) []interface{} {
+	return []interface{}{e.truthy(v)}
+}
+
+func (e compareExpr) truthy(v interface{}) bool {
+	for _, l := range e.left.values(v) {
+		for _, r := range e.right.values(v) {
+			if compareValues(e.op, l, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTruthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+// numericOperators maps the comparison operators of queries to the ones of compareNumbers.
+var numericOperators = map[string]string{"<": "lt", "<=": "lte", ">": "gt", ">=": "gte"}
+
+func compareValues(op string, l, r interface{}) bool {
+	switch op {
+	case "==":
+		return reflect.DeepEqual(l, r)
+	case "!=":
+		return !reflect.DeepEqual(l, r)
+	}
+
+	if lf, ok := l.(float64); ok {
+		if rf, ok := r.(float64); ok {
+			return compareNumbers(numericOperators[op], lf, rf)
+		}
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			switch op {
+			case "<":
+				return ls < rs
+			case "<=":
+				return ls <= rs
+			case ">":
+				return ls > rs
+			case ">=":
+				return ls >= rs
+			}
+		}
+	}
+	return false
+}
+
+// queryParser is a recursive descent parser for queries.
+type queryParser struct {
+	query string
+	pos   int
+}
+
+func (p *queryParser) parse() (*Query, error) {
+	q := &Query{query: p.query, singular: true}
+
+	p.skipSpace()
+	// The leading dot may be omitted, e.g. "subjects" or "a.b".
+	if p.pos < len(p.query) && isNameByte(p.query[p.pos]) && !p.hasKeyword("select(") {
+		p.query = p.query[:p.pos] + "." + p.query[p.pos:]
+	}
+
+	for {
+		steps, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		q.steps = append(q.steps, steps...)
+
+		p.skipSpace()
+		if p.pos == len(p.query) {
+			break
+		}
+		if p.query[p.pos] != '|' {
+			return nil, p.errorf("unexpected %q", p.query[p.pos])
+		}
+		p.pos++
+	}
+
+	for _, s := range q.steps {
+		switch s.(type) {
+		case fieldStep, indexStep, sliceStep:
+		default:
+			q.singular = false
+		}
+	}
+	return q, nil
+}
+
+// parseTerm parses a path or select(...) followed by any number of path segments.
+func (p *queryParser) parseTerm() ([]step, error) {
+	p.skipSpace()
+	var steps []step
+	switch {
+	case p.hasKeyword("select("):
+		p.pos += len("select(")
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		steps = append(steps, selectStep{cond: cond})
+	case p.pos < len(p.query) && (p.query[p.pos] == '$' || p.query[p.pos] == '@'):
+		p.pos++
+	case p.pos < len(p.query) && (p.query[p.pos] == '.' || p.query[p.pos] == '['):
+	default:
+		return nil, p.errorf("expected a path")
+	}
+
+	for p.pos < len(p.query) {
+		switch c := p.query[p.pos]; {
+		case strings.HasPrefix(p.query[p.pos:], ".."):
+			p.pos += 2
+			steps = append(steps, recurseStep{})
+			if p.pos < len(p.query) && (isNameByte(p.query[p.pos]) || p.query[p.pos] == '"') {
+				s, err := p.parseField()
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, s)
+			}
+		case c == '.':
+			p.pos++
+			if p.pos == len(p.query) {
+				break
+			}
+			switch n := p.query[p.pos]; {
+			case n == '*':
+				p.pos++
+				steps = append(steps, iterateStep{})
+			case n == '"' || isNameByte(n):
+				s, err := p.parseField()
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, s)
+			}
+		case c == '[':
+			s, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s...)
+		default:
+			return steps, nil
+		}
+	}
+	return steps, nil
+}
+
+func (p *queryParser) parseField() (step, error) {
+	if p.query[p.pos] == '"' || p.query[p.pos] == '\'' {
+		s, err := p.parseString()
+		return fieldStep(s), err
+	}
+	start := p.pos
+	for p.pos < len(p.query) && isNameByte(p.query[p.pos]) {
+		p.pos++
+	}
+	return fieldStep(p.query[start:p.pos]), nil
+}
+
+func (p *queryParser) parseBracket() ([]step, error) {
+	p.pos++
+	p.skipSpace()
+	if p.pos == len(p.query) {
+		return nil, p.errorf("unclosed [")
+	}
+
+	var steps []step
+	switch c := p.query[p.pos]; {
+	case c == ']':
+		steps = []step{iterateStep{}}
+	case c == '*':
+		p.pos++
+		steps = []step{iterateStep{}}
+	case c == '"' || c == '\'':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		steps = []step{fieldStep(s)}
+	case c == '?':
+		p.pos++
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		steps = []step{iterateStep{}, selectStep{cond: cond}}
+	default:
+		from, hasFrom, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos < len(p.query) && p.query[p.pos] == ':' {
+			p.pos++
+			to, hasTo, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			steps = []step{sliceStep{from: from, hasFrom: hasFrom, to: to, hasTo: hasTo}}
+		} else if !hasFrom {
+			return nil, p.errorf("expected an index")
+		} else {
+			steps = []step{indexStep(from)}
+		}
+	}
+
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (p *queryParser) parseInt() (int, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.query) && p.query[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.query) && p.query[p.pos] >= '0' && p.query[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(p.query[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid index %q", p.query[start:p.pos])
+	}
+	return i, true, nil
+}
+
+// parseString parses a double quoted json string or a single quoted string as used by JSONPath.
+func (p *queryParser) parseString() (string, error) {
+	quote := p.query[p.pos]
+	end := p.pos + 1
+	for ; end < len(p.query) && p.query[end] != quote; end++ {
+		if p.query[end] == '\\' {
+			end++
+		}
+	}
+	if end >= len(p.query) {
+		return "", p.errorf("unterminated string")
+	}
+
+	raw := p.query[p.pos : end+1]
+	p.pos = end + 1
+	if quote == '\'' {
+		raw = `"` + strings.Replace(strings.Replace(raw[1:len(raw)-1], `\'`, `'`, -1), `"`, `\"`, -1) + `"`
+	}
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return "", p.errorf("invalid string %s", raw)
+	}
+	return s, nil
+}
+
+func (p *queryParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.hasKeyword("or") || p.hasKeyword("||"); p.skipSpace() {
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (expr, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.hasKeyword("and") || p.hasKeyword("&&"); p.skipSpace() {
+		if p.hasKeyword("and") {
+			p.pos += 3
+		} else {
+			p.pos += 2
+		}
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseCompare() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.query[p.pos:], op) {
+			p.pos += len(op)
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseOperand() (expr, error) {
+	p.skipSpace()
+	if p.pos == len(p.query) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	switch c := p.query[p.pos]; {
+	case c == '(':
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(')')
+	case c == '"' || c == '\'':
+		s, err := p.parseString()
+		return literalExpr{value: s}, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.query) && strings.IndexByte("0123456789.eE+-", p.query[p.pos]) >= 0 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.query[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.query[start:p.pos])
+		}
+		return literalExpr{value: f}, nil
+	case p.hasKeyword("true"):
+		p.pos += 4
+		return literalExpr{value: true}, nil
+	case p.hasKeyword("false"):
+		p.pos += 5
+		return literalExpr{value: false}, nil
+	case p.hasKeyword("null"):
+		p.pos += 4
+		return literalExpr{value: nil}, nil
+	default:
+		steps, err := p.parseTerm()
+		return pathExpr{steps: steps}, err
+	}
+}
+
+// hasKeyword returns true if the query continues with the keyword, which must not be followed by a name if
+// it is a name itself.
+func (p *queryParser) hasKeyword(keyword string) bool {
+	if !strings.HasPrefix(p.query[p.pos:], keyword) {
+		return false
+	}
+	end := p.pos + len(keyword)
+	return !isNameByte(keyword[len(keyword)-1]) || end == len(p.query) || !isNameByte(p.query[end])
+}
+
+func (p *queryParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos == len(p.query) || p.query[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.query) && strings.IndexByte(" \t\r\n", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+// isNameByte returns true if c can be part of an unquoted field name. Besides letters, digits and underscores,
+// this includes other characters without a meaning in queries, e.g. "-" or ":", and any non-ASCII character.
+func isNameByte(c byte) bool {
+	return c >= utf8.RuneSelf || strings.IndexByte(".[]|()=!<>,&\"'@$*? \t\r\n", c) < 0
+}
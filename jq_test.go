@@ -0,0 +1,121 @@
+package ladon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queryDocument = `{
+	"effect": "allow",
+	"subjects": ["roles:admin", "roles:editor", "users:peter"],
+	"a.b": {"c": 1},
+	"items": [
+		{"id": "1", "type": "x", "size": 5, "tags": ["new"]},
+		{"id": "2", "type": "y", "size": 20, "owner": {"name": "max"}},
+		{"id": "3", "type": "x", "size": 30, "tags": ["sale", "new"]}
+	],
+	"owner": {"name": "peter"},
+	"empty": null
+}`
+
+func TestQuery(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(queryDocument), &doc))
+
+	for k, c := range []struct {
+		query    string
+		expected interface{}
+		missing  bool
+	}{
+		{query: ".", expected: doc},
+		{query: ".effect", expected: "allow"},
+		{query: "effect", expected: "allow"},
+		{query: "$.effect", expected: "allow"},
+		{query: ".owner.name", expected: "peter"},
+		{query: "owner.name", expected: "peter"},
+		{query: ".subjects[0]", expected: "roles:admin"},
+		{query: ".subjects.[1]", expected: "roles:editor"},
+		{query: ".subjects[-1]", expected: "users:peter"},
+		{query: ".subjects[3]", missing: true},
+		{query: ".subjects[-4]", missing: true},
+		{query: ".subjects[1:]", expected: []interface{}{"roles:editor", "users:peter"}},
+		{query: ".subjects[:-1]", expected: []interface{}{"roles:admin", "roles:editor"}},
+		{query: ".subjects[5:9]", expected: []interface{}{}},
+		{query: `."a.b".c`, expected: float64(1)},
+		{query: `.["a.b"]["c"]`, expected: float64(1)},
+		{query: `$['a.b']['c']`, expected: float64(1)},
+		{query: ".missing", missing: true},
+		{query: ".effect.missing", missing: true},
+		{query: ".empty", expected: nil},
+		{query: ".items[*].id", expected: []interface{}{"1", "2", "3"}},
+		{query: ".items[].id", expected: []interface{}{"1", "2", "3"}},
+		{query: ".items | .[] | .id", expected: []interface{}{"1", "2", "3"}},
+		{query: ".owner.*", expected: []interface{}{"peter"}},
+		{query: "..name", expected: []interface{}{"max", "peter"}},
+		{query: `..["name"]`, expected: []interface{}{"max", "peter"}},
+		{query: `.items[] | select(.type == "x") | .id`, expected: []interface{}{"1", "3"}},
+		{query: `.items[] | select(.type == "x" and .size >= 10).id`, expected: []interface{}{"3"}},
+		{query: `.items[] | select(.type == "y" or .size < 10) | .id`, expected: []interface{}{"1", "2"}},
+		{query: `.items[] | select(.tags[] == "sale") | .id`, expected: []interface{}{"3"}},
+		{query: `.items[] | select(.owner) | .id`, expected: []interface{}{"2"}},
+		{query: `.items[] | select(.owner.name != "max") | .id`, expected: []interface{}{"1", "3"}},
+		{query: `.items[] | select((.size > 10) and (.type != "x")) | .id`, expected: []interface{}{"2"}},
+		{query: `.items[?(@.size > 10)].id`, expected: []interface{}{"2", "3"}},
+		{query: `.items[] | select(.missing == null) | .id`, expected: []interface{}{"1", "2", "3"}},
+		{query: `.subjects[] | select(. == "users:peter")`, expected: []interface{}{"users:peter"}},
+		{query: `.items[] | select(.id > "1" and .id <= "3") | .size`, expected: []interface{}{float64(20), float64(30)}},
+		{query: `.items[] | select(.size == -5)`, missing: true},
+		{query: ".items[].missing", missing: true},
+		{query: ".missing[]", missing: true},
+		{query: `.items[] | select(true) | .id`, expected: []interface{}{"1", "2", "3"}},
+	} {
+		q, err := CompileQuery(c.query)
+		require.NoError(t, err, "case %d: %s", k, c.query)
+		v, ok := q.Get(doc)
+		assert.Equal(t, !c.missing, ok, "case %d: %s", k, c.query)
+		assert.Equal(t, c.expected, v, "case %d: %s", k, c.query)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		".a[",
+		".a[x]",
+		`.a["b]`,
+		".a]",
+		"select(.a ==)",
+		"select(.a == 1",
+		".a | | .b",
+	} {
+		_, err := CompileQuery(query)
+		assert.Error(t, err, "%s", query)
+	}
+}
+
+func TestJsonQuery(t *testing.T) {
+	data, err := JsonQuery([]byte(queryDocument), ".items[1].owner")
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"max"}`, string(data))
+
+	data, err = JsonQuery([]byte(queryDocument), ".items[].size")
+	require.NoError(t, err)
+	assert.Equal(t, `[5,20,30]`, string(data))
+
+	s, err := String(JsonQuery([]byte(queryDocument), ".subjects[0]"))
+	require.NoError(t, err)
+	assert.Equal(t, "roles:admin", s)
+
+	s, err = String(JsonQuery([]byte(queryDocument), ".items[0].size"))
+	require.NoError(t, err)
+	assert.Equal(t, "5", s)
+
+	_, err = JsonQuery([]byte(queryDocument), ".items[7]")
+	assert.Error(t, err)
+
+	_, err = JsonQuery([]byte(`null`), ".a")
+	assert.Error(t, err)
+}
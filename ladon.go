@@ -1,47 +1,110 @@
 package ladon
 
 import (
-	"log"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // Ladon is an implementation of Warden.
 type Ladon struct {
-	Manager Manager
-	Matcher matcher
+	Manager     Manager
+	Matcher     Matcher
+	AuditLogger AuditLogger
+	Logger      Logger
+
+	// BatchConcurrency limits the number of requests IsAllowedBatch evaluates at the same time.
+	// It defaults to the number of CPUs.
+	BatchConcurrency int
+
+	// Clock returns the current time for requests without a time. It defaults to time.Now.
+	Clock func() time.Time
+
+	// MaxBodySize is the maximum number of bytes of the raw http request's body which body conditions read.
+	// Larger bodies fail the conditions with an error. It defaults to DefaultMaxBodySize.
+	MaxBodySize int64
 }
 
-func (l *Ladon) matcher() matcher {
+func (l *Ladon) matcher() Matcher {
 	if l.Matcher == nil {
 		l.Matcher = DefaultMatcher
 	}
 	return l.Matcher
 }
 
+// prepare returns a copy of the request for an authorization call. Its time is set to the clock's time,
+// unless it already has one, and the body of the raw http request is decoded at most once, unless the
+// request already shares a decoded body (see IsAllowedBatch).
+func (l *Ladon) prepare(r *Request) *Request {
+	tr := *r
+	if tr.body == nil {
+		tr.body = newRequestBody(l.MaxBodySize)
+	}
+	if !tr.Time.IsZero() {
+		return &tr
+	}
+
+	if l.Clock != nil {
+		tr.Time = l.Clock()
+	} else {
+		tr.Time = time.Now()
+	}
+	return &tr
+}
+
+func (l *Ladon) logger() Logger {
+	if l.Logger == nil {
+		return NopLogger
+	}
+	return l.Logger
+}
+
 // IsAllowed returns nil if subject s has permission p on resource r with context c or an error otherwise.
 func (l *Ladon) IsAllowed(r *Request) (err error) {
 	policies, err := l.Manager.FindRequestCandidates(r)
+	// Prepare the request once so that the decision and its audit entry share the same time.
+	r = l.prepare(r)
 	if err != nil {
-		return err
+		l.logger().Error("could not find request candidates", "error", err)
+		return l.audit(r, nil, nil, err)
 	}
-	log.Println("[DEBUG] Policies to check:", len(policies))
+	return l.isAllowed(r, policies)
+}
+
+func (l *Ladon) isAllowed(r *Request, policies Policies) error {
+	l.logger().Debug("policies to check", "count", len(policies))
 	// Although the manager is responsible of matching the policies, it might decide to just scan for
 	// subjects, it might return all policies, or it might have a different pattern matching than Golang.
 	// Thus, we need to make sure that we actually matched the right policies.
-	return l.doPoliciesAllow(r, policies)
+	decider, err := l.doPoliciesAllow(r, policies)
+	return l.audit(r, policies, decider, err)
 }
 
-func (l *Ladon) doPoliciesAllow(r *Request, policies []Policy) (err error) {
-	var allowed = false
+// audit hands the decision to the audit logger, if one is set. If the decision can not be recorded,
+// the request is denied with the audit logger's error.
+func (l *Ladon) audit(r *Request, policies Policies, decider Policy, err error) error {
+	if l.AuditLogger == nil {
+		return err
+	}
+	if aerr := l.AuditLogger.LogDecision(r, policies, decider, err); aerr != nil {
+		l.logger().Error("could not record decision", "error", aerr)
+		return errors.WithStack(aerr)
+	}
+	return err
+}
+
+// doPoliciesAllow returns the policy which decided the request. The policy is nil if no policy matched.
+func (l *Ladon) doPoliciesAllow(r *Request, policies []Policy) (Policy, error) {
+	var allowedBy Policy
+	r = l.prepare(r)
 
 	// Iterate through all policies
 	for _, p := range policies {
 		// Does the action match with one of the policies?
 		// This is the first check because usually actions are a superset of get|update|delete|set
 		// and thus match faster.
-		if pm, err := l.matcher().Matches(p, p.GetActions(), r.Action); err != nil {
-			return errors.WithStack(err)
+		if pm, err := l.matcher().Matches(p, ResolveVariables(p, p.GetActions(), r), r.Action); err != nil {
+			return nil, errors.WithStack(err)
 		} else if !pm {
 			// no, continue to next policy
 			continue
@@ -60,42 +123,52 @@ func (l *Ladon) doPoliciesAllow(r *Request, policies []Policy) (err error) {
 		// Iterate through supplied subjects
 		matchedSubs, err := l.checkSubjects(p, r)
 		if err != nil {
-			return err
+			return nil, err
 		} else if !matchedSubs {
 			continue
 		}
 
 		// Does the resource match with one of the policies?
-		if rm, err := l.matcher().Matches(p, p.GetResources(), r.Resource); err != nil {
-			return errors.WithStack(err)
+		if rm, err := l.matcher().Matches(p, ResolveVariables(p, p.GetResources(), r), r.Resource); err != nil {
+			return nil, errors.WithStack(err)
 		} else if !rm {
 			// no, continue to next policy
 			continue
 		}
 
+		// Make the values captured by the policy's patterns available to its conditions.
+		vr, err := l.withVariables(p, r)
+		if err != nil {
+			return nil, err
+		}
+
 		// Are the policies conditions met?
 		// This is checked first because it usually has a small complexity.
-		if !l.passesConditions(p, r) {
+		if pass, err := l.passesConditions(p, vr); err != nil {
+			return nil, err
+		} else if !pass {
 			// no, continue to next policy
 			continue
 		}
 
 		// Is the policies effect deny? If yes, this overrides all allow policies -> access denied.
 		if !p.AllowAccess() {
-			return errors.WithStack(ErrRequestForcefullyDenied)
+			return p, errors.WithStack(ErrRequestForcefullyDenied)
+		}
+		if allowedBy == nil {
+			allowedBy = p
 		}
-		allowed = true
 	}
 
-	if !allowed {
-		return errors.WithStack(ErrRequestDenied)
+	if allowedBy == nil {
+		return nil, errors.WithStack(ErrRequestDenied)
 	}
 
-	return nil
+	return allowedBy, nil
 }
 
 func (l *Ladon) checkSubjects(p Policy, r *Request) (bool, error) {
-	subjects := p.GetSubjects()
+	subjects := ResolveVariables(p, p.GetSubjects(), r)
 
 	for _, sub := range r.Subjects {
 		// Does the subject match with one of the policies?
@@ -114,11 +187,32 @@ func (l *Ladon) checkSubjects(p Policy, r *Request) (bool, error) {
 	return false, nil
 }
 
-func (l *Ladon) passesConditions(p Policy, r *Request) bool {
-	for key, condition := range p.GetConditions() {
-		if pass := condition.Fulfills(r.Context[key], r); !pass {
-			return false
+// passesConditions evaluates the conditions in order of their keys, so the same condition decides the
+// request every time.
+func (l *Ladon) passesConditions(p Policy, r *Request) (bool, error) {
+	conditions := p.GetConditions()
+	for _, key := range conditions.keys() {
+		if pass, err := l.evaluateCondition(p, key, conditions[key], r); err != nil {
+			return false, err
+		} else if !pass {
+			return false, nil
 		}
 	}
-	return true
+	return true, nil
+}
+
+// evaluateCondition evaluates the condition of the policy stored under key. Errors are returned as
+// ConditionError.
+func (l *Ladon) evaluateCondition(p Policy, key string, c Condition, r *Request) (bool, error) {
+	pass, err := EvaluateCondition(c, r.Context[key], r)
+	if err != nil {
+		l.logger().Error("could not evaluate condition", "policy", p.GetID(), "key", key, "error", err)
+		return false, errors.WithStack(&ConditionError{
+			Policy:    p.GetID(),
+			Key:       key,
+			Condition: c.GetName(),
+			Err:       err,
+		})
+	}
+	return pass, nil
 }
@@ -0,0 +1,29 @@
+package ladon
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLogger(log.New(&buf, "", 0), LevelWarn)
+
+	l.Debug("debug message")
+	l.Info("info message")
+	assert.Equal(t, "", buf.String())
+
+	l.Warn("slow query", "policy", "1", "duration", "2s")
+	assert.Equal(t, "[WARN] slow query policy=1 duration=2s\n", buf.String())
+
+	buf.Reset()
+	l.Error("odd", "key")
+	assert.Equal(t, "[ERROR] odd key=<missing>\n", buf.String())
+}
+
+func TestLadonDefaultsToNopLogger(t *testing.T) {
+	assert.Equal(t, NopLogger, new(Ladon).logger())
+}
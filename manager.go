@@ -22,4 +22,20 @@ type Manager interface {
 	// a set that exactly matches the request, or a superset of it. If an error occurs, it returns nil and
 	// the error.
 	FindRequestCandidates(r *Request) (Policies, error)
+
+	// FindPoliciesForSubject returns all policies that could apply to the subject, regardless of
+	// resource and action. It either returns a set that exactly matches the subject, or a superset of it.
+	FindPoliciesForSubject(subject string) (Policies, error)
+
+	// FindPoliciesForResource returns all policies that could apply to the resource, regardless of
+	// subject and action. It either returns a set that exactly matches the resource, or a superset of it.
+	FindPoliciesForResource(resource string) (Policies, error)
+}
+
+// BatchManager is implemented by managers which can look up all policies of a set of subjects at once,
+// regardless of resource and action. It allows IsAllowedBatch to fetch candidates once per subject set.
+type BatchManager interface {
+	// FindSubjectCandidates returns all policies that could match one of the subjects. It either returns
+	// a set that exactly matches the subjects, or a superset of it.
+	FindSubjectCandidates(subjects []string) (Policies, error)
 }
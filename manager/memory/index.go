@@ -0,0 +1,120 @@
+package memory
+
+import (
+	. "github.com/d3sw/ladon"
+)
+
+// index maps the literal patterns of one policy field (subjects, actions or resources) to the IDs of the
+// policies containing them. Policies with at least one wildcard or regular expression in that field are kept in a
+// separate bucket because they might match any value.
+type index struct {
+	literal map[string]map[string]struct{}
+	regexp  map[string]struct{}
+}
+
+func newIndex() *index {
+	return &index{
+		literal: map[string]map[string]struct{}{},
+		regexp:  map[string]struct{}{},
+	}
+}
+
+func (i *index) add(p Policy, patterns []string) {
+	id := p.GetID()
+	for _, pattern := range patterns {
+		if !IsLiteral(p, pattern) {
+			i.regexp[id] = struct{}{}
+			continue
+		}
+		ids, ok := i.literal[pattern]
+		if !ok {
+			ids = map[string]struct{}{}
+			i.literal[pattern] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+func (i *index) remove(id string, patterns []string) {
+	delete(i.regexp, id)
+	for _, pattern := range patterns {
+		if ids, ok := i.literal[pattern]; ok {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(i.literal, pattern)
+			}
+		}
+	}
+}
+
+// has returns true if the policy might match the value.
+func (i *index) has(id, value string) bool {
+	if _, ok := i.regexp[id]; ok {
+		return true
+	}
+	_, ok := i.literal[value][id]
+	return ok
+}
+
+// candidates calls fn for every policy that might match one of the values. fn may be called more
+// than once for the same policy.
+func (i *index) candidates(values []string, fn func(id string)) {
+	for id := range i.regexp {
+		fn(id)
+	}
+	for _, value := range values {
+		for id := range i.literal[value] {
+			fn(id)
+		}
+	}
+}
+
+// indexed is a copy of the patterns a policy was indexed with. The policy itself can not be used to
+// remove it from the indexes, because it might have been modified in the meantime.
+type indexed struct {
+	subjects  []string
+	actions   []string
+	resources []string
+}
+
+type indexes struct {
+	subjects  *index
+	actions   *index
+	resources *index
+	policies  map[string]*indexed
+}
+
+func newIndexes() *indexes {
+	return &indexes{
+		subjects:  newIndex(),
+		actions:   newIndex(),
+		resources: newIndex(),
+		policies:  map[string]*indexed{},
+	}
+}
+
+func (i *indexes) add(p Policy) {
+	i.remove(p.GetID())
+
+	e := &indexed{
+		subjects:  append([]string{}, p.GetSubjects()...),
+		actions:   append([]string{}, p.GetActions()...),
+		resources: append([]string{}, p.GetResources()...),
+	}
+	i.policies[p.GetID()] = e
+
+	i.subjects.add(p, e.subjects)
+	i.actions.add(p, e.actions)
+	i.resources.add(p, e.resources)
+}
+
+func (i *indexes) remove(id string) {
+	e, ok := i.policies[id]
+	if !ok {
+		return
+	}
+	i.subjects.remove(id, e.subjects)
+	i.actions.remove(id, e.actions)
+	i.resources.remove(id, e.resources)
+	delete(i.policies, id)
+}
@@ -8,24 +8,54 @@ import (
 	"github.com/pkg/errors"
 )
 
-// MemoryManager is an in-memory (non-persistent) implementation of Manager.
+// MemoryManager is an in-memory (non-persistent) implementation of Manager. It indexes the literal subjects,
+// actions and resources of all policies, so Policies must only be changed through Create, Update and Delete.
 type MemoryManager struct {
 	Policies map[string]Policy
+	Logger   Logger
 	sync.RWMutex
+
+	indexes *indexes
 }
 
 // NewMemoryManager constructs and initializes new MemoryManager with no policies.
 func NewMemoryManager() *MemoryManager {
 	return &MemoryManager{
 		Policies: map[string]Policy{},
+		indexes:  newIndexes(),
+	}
+}
+
+// index adds the policy to the indexes. The caller must hold the write lock.
+func (m *MemoryManager) index(policy Policy) {
+	if m.indexes == nil {
+		m.indexes = newIndexes()
+		for _, p := range m.Policies {
+			m.indexes.add(p)
+		}
 	}
+	m.indexes.add(policy)
+}
+
+func (m *MemoryManager) logger() Logger {
+	if m.Logger == nil {
+		return NopLogger
+	}
+	return m.Logger
 }
 
 // Update updates an existing policy.
 func (m *MemoryManager) Update(policy Policy) error {
+	if err := policy.GetConditions().Validate(); err != nil {
+		m.logger().Warn("invalid policy", "policy", policy.GetID(), "error", err)
+		return err
+	}
+
 	m.Lock()
 	defer m.Unlock()
 	m.Policies[policy.GetID()] = policy
+	m.index(policy)
+	m.logger().Debug("policy updated", "policy", policy.GetID())
 	return nil
 }
 
@@ -50,7 +80,7 @@ func (m *MemoryManager) GetAll(limit, offset int64) (Policies, error) {
 		v, _ := m.Policies[key]
 		ps[i] = v
 		i++
-		if i > max {
+		if int64(i) > max {
 			break
 		}
 	}
@@ -60,14 +90,22 @@ func (m *MemoryManager) GetAll(limit, offset int64) (Policies, error) {
 
 // Create a new pollicy to MemoryManager.
 func (m *MemoryManager) Create(policy Policy) error {
+	if err := policy.GetConditions().Validate(); err != nil {
+		m.logger().Warn("invalid policy", "policy", policy.GetID(), "error", err)
+		return err
+	}
+
 	m.Lock()
 	defer m.Unlock()
 
 	if _, found := m.Policies[policy.GetID()]; found {
+		m.logger().Warn("policy exists", "policy", policy.GetID())
 		return errors.New("Policy exists")
 	}
 
 	m.Policies[policy.GetID()] = policy
+	m.index(policy)
+	m.logger().Debug("policy created", "policy", policy.GetID())
 	return nil
 }
 
@@ -88,6 +126,10 @@ func (m *MemoryManager) Delete(id string) error {
 	m.Lock()
 	defer m.Unlock()
 	delete(m.Policies, id)
+	if m.indexes != nil {
+		m.indexes.remove(id)
+	}
+	m.logger().Debug("policy deleted", "policy", id)
 	return nil
 }
 
@@ -97,11 +139,72 @@ func (m *MemoryManager) Delete(id string) error {
 func (m *MemoryManager) FindRequestCandidates(r *Request) (Policies, error) {
 	m.RLock()
 	defer m.RUnlock()
-	ps := make(Policies, len(m.Policies))
-	var count int
-	for _, p := range m.Policies {
-		ps[count] = p
-		count++
+	if m.indexes == nil {
+		return m.all(), nil
 	}
+
+	var ps Policies
+	seen := map[string]struct{}{}
+	m.indexes.subjects.candidates(r.Subjects, func(id string) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		if m.indexes.actions.has(id, r.Action) && m.indexes.resources.has(id, r.Resource) {
+			ps = append(ps, m.Policies[id])
+		}
+	})
 	return ps, nil
 }
+
+// FindSubjectCandidates returns candidates that could match one of the subjects. It either returns
+// a set that exactly matches the subjects, or a superset of it.
+func (m *MemoryManager) FindSubjectCandidates(subjects []string) (Policies, error) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.indexes == nil {
+		return m.all(), nil
+	}
+	return m.collect(m.indexes.subjects, subjects), nil
+}
+
+// FindPoliciesForSubject returns all policies that could apply to the subject. It either returns
+// a set that exactly matches the subject, or a superset of it.
+func (m *MemoryManager) FindPoliciesForSubject(subject string) (Policies, error) {
+	return m.FindSubjectCandidates([]string{subject})
+}
+
+// FindPoliciesForResource returns all policies that could apply to the resource. It either returns
+// a set that exactly matches the resource, or a superset of it.
+func (m *MemoryManager) FindPoliciesForResource(resource string) (Policies, error) {
+	m.RLock()
+	defer m.RUnlock()
+	if m.indexes == nil {
+		return m.all(), nil
+	}
+	return m.collect(m.indexes.resources, []string{resource}), nil
+}
+
+// collect returns all policies that might match one of the values according to the index. The caller
+// must hold the read lock.
+func (m *MemoryManager) collect(i *index, values []string) Policies {
+	var ps Policies
+	seen := map[string]struct{}{}
+	i.candidates(values, func(id string) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		ps = append(ps, m.Policies[id])
+	})
+	return ps
+}
+
+// all returns all policies. The caller must hold the read lock.
+func (m *MemoryManager) all() Policies {
+	ps := make(Policies, 0, len(m.Policies))
+	for _, p := range m.Policies {
+		ps = append(ps, p)
+	}
+	return ps
+}
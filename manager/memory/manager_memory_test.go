@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryManager(t *testing.T) {
+	t.Run("type=get-errors", TestHelperGetErrors(NewMemoryManager()))
+	t.Run("type=create-get-delete", TestHelperCreateGetDelete(NewMemoryManager()))
+	t.Run("type=find-policies-for-subject", TestHelperFindPoliciesForSubject("memory", NewMemoryManager()))
+	t.Run("type=invalid-conditions", TestHelperInvalidConditions(NewMemoryManager()))
+}
+
+func TestMemoryManagerIndex(t *testing.T) {
+	m := NewMemoryManager()
+	p := &DefaultPolicy{
+		ID:        "1",
+		Subjects:  []string{"peter"},
+		Actions:   []string{"view"},
+		Resources: []string{"articles:<[0-9]+>"},
+		Effect:    AllowAccess,
+	}
+	require.NoError(t, m.Create(p))
+	require.NoError(t, m.Create(&DefaultPolicy{
+		ID:        "2",
+		Subjects:  []string{"<max|ken>"},
+		Actions:   []string{"view", "delete"},
+		Resources: []string{"articles:1"},
+		Effect:    AllowAccess,
+	}))
+
+	find := func(subject, action, resource string) []string {
+		ps, err := m.FindRequestCandidates(&Request{Subjects: []string{subject}, Action: action, Resource: resource})
+		require.NoError(t, err)
+		ids := []string{}
+		for _, p := range ps {
+			ids = append(ids, p.GetID())
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1"}, find("peter", "view", "articles:2"))
+	assert.Equal(t, []string{}, find("peter", "delete", "articles:2"))
+	assert.Equal(t, []string{"2"}, find("max", "delete", "articles:1"))
+	assert.Equal(t, []string{}, find("max", "delete", "articles:2"))
+
+	// The index must follow policies which were modified in place.
+	p.Subjects = []string{"zac"}
+	require.NoError(t, m.Update(p))
+	assert.Equal(t, []string{}, find("peter", "view", "articles:2"))
+	assert.Equal(t, []string{"1"}, find("zac", "view", "articles:2"))
+
+	require.NoError(t, m.Delete("2"))
+	assert.Equal(t, []string{}, find("max", "delete", "articles:1"))
+
+	ps, err := m.FindPoliciesForResource("articles:1")
+	require.NoError(t, err)
+	assert.Len(t, ps, 1)
+}
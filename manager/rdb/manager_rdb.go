@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/d3sw/ladon"
 	"github.com/pkg/errors"
@@ -13,6 +14,12 @@ type RdbManager struct {
 	session *r.Session
 	table   r.Term
 	s       SchemaManager
+
+	// Logger receives slow query and decode failure reports. It defaults to ladon.NopLogger.
+	Logger Logger
+
+	// SlowQuery is the duration after which a query is reported as slow. Zero disables the report.
+	SlowQuery time.Duration
 }
 
 // NewRdbManager initializes a new RdbManager for given session.
@@ -24,8 +31,37 @@ func NewRdbManager(session *r.Session, table string, s SchemaManager) *RdbManage
 	}
 }
 
+func (m *RdbManager) logger() Logger {
+	if m.Logger == nil {
+		return NopLogger
+	}
+	return m.Logger
+}
+
+// observe reports the query as slow if it took longer than SlowQuery.
+func (m *RdbManager) observe(query string, start time.Time, keyvals ...interface{}) {
+	d := time.Since(start)
+	if m.SlowQuery <= 0 || d < m.SlowQuery {
+		return
+	}
+	m.logger().Warn("slow query", append([]interface{}{"query", query, "duration", d}, keyvals...)...)
+}
+
+// decode converts a schema to a policy and reports schemas that can not be decoded.
+func (m *RdbManager) decode(s Schema) (Policy, error) {
+	p, err := s.GetPolicy()
+	if err != nil {
+		m.logger().Error("could not decode policy", "policy", s.GetID(), "error", err)
+		return nil, err
+	}
+	return p, nil
+}
+
 // Create inserts a new policy.
 func (m *RdbManager) Create(policy Policy) error {
+	if err := policy.GetConditions().Validate(); err != nil {
+		return err
+	}
 	s := m.s.NewSchema()
 	s.PopulateWithPolicy(policy)
 	if _, err := m.table.Insert(s).RunWrite(m.session); err != nil {
@@ -36,6 +72,9 @@ func (m *RdbManager) Create(policy Policy) error {
 
 // Update updates an existing policy.
 func (m *RdbManager) Update(policy Policy) error {
+	if err := policy.GetConditions().Validate(); err != nil {
+		return err
+	}
 	s := m.s.NewSchema()
 	s.PopulateWithPolicy(policy)
 	if _, err := m.table.Get(s.GetID()).Update(s).RunWrite(m.session); err != nil {
@@ -46,7 +85,9 @@ func (m *RdbManager) Update(policy Policy) error {
 
 // Get retrieves a policy.
 func (m *RdbManager) Get(id string) (Policy, error) {
+	start := time.Now()
 	res, err := m.table.Get(id).Run(m.session)
+	m.observe("get", start, "policy", id)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -57,7 +98,7 @@ func (m *RdbManager) Get(id string) (Policy, error) {
 			return nil, v.Err
 		}
 
-		p, err := v.Schema.GetPolicy()
+		p, err := m.decode(v.Schema)
 		if err != nil {
 			return nil, err
 		}
@@ -78,7 +119,9 @@ func (m *RdbManager) Delete(id string) error {
 
 // GetAll returns all policies.
 func (m *RdbManager) GetAll(limit, offset int64) (Policies, error) {
+	start := time.Now()
 	res, err := m.table.Skip(offset).Limit(limit).Run(m.session)
+	m.observe("get_all", start, "limit", limit, "offset", offset)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -89,7 +132,7 @@ func (m *RdbManager) GetAll(limit, offset int64) (Policies, error) {
 		if s.Err != nil {
 			return nil, s.Err
 		}
-		p, err := s.Schema.GetPolicy()
+		p, err := m.decode(s.Schema)
 		if err != nil {
 			return policies, errors.WithStack(err)
 		}
@@ -115,7 +158,9 @@ func (m *RdbManager) FindRequestCandidates(req *Request) (Policies, error) {
 	}
 
 	for _, s := range req.Subjects {
+		start := time.Now()
 		res, err := m.s.GetRequestCandidatesTerm(m.table, s, req.Resource, req.Action).Run(m.session)
+		m.observe("find_request_candidates", start, "subject", s, "resource", req.Resource, "action", req.Action)
 		if err != nil {
 			return nil, err
 		}
@@ -126,7 +171,7 @@ func (m *RdbManager) FindRequestCandidates(req *Request) (Policies, error) {
 			}
 
 			if _, ok := mp[v.Schema.GetID()]; !ok {
-				p, err := v.Schema.GetPolicy()
+				p, err := m.decode(v.Schema)
 				if err != nil {
 					return nil, errors.WithStack(err)
 				}
@@ -140,3 +185,47 @@ func (m *RdbManager) FindRequestCandidates(req *Request) (Policies, error) {
 	}
 	return policies, nil
 }
+
+// FindSubjectCandidates returns candidates that could match one of the subjects with a single query.
+// It either returns a set that exactly matches the subjects, or a superset of it.
+func (m *RdbManager) FindSubjectCandidates(subjects []string) (Policies, error) {
+	if len(subjects) == 0 {
+		return nil, errors.New("missing subjects")
+	}
+
+	return m.find("find_subject_candidates", m.s.GetSubjectCandidatesTerm(m.table, subjects), "subjects", subjects)
+}
+
+// find runs the query and decodes all policies it returns.
+func (m *RdbManager) find(query string, term r.Term, keyvals ...interface{}) (Policies, error) {
+	start := time.Now()
+	res, err := term.Run(m.session)
+	m.observe(query, start, keyvals...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer res.Close()
+
+	var policies Policies
+	for v := range m.s.ProcessResult(res) {
+		if v.Err != nil {
+			return nil, errors.WithStack(v.Err)
+		}
+		p, err := m.decode(v.Schema)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// FindPoliciesForSubject returns all policies whose compiled subjects match the subject.
+func (m *RdbManager) FindPoliciesForSubject(subject string) (Policies, error) {
+	return m.FindSubjectCandidates([]string{subject})
+}
+
+// FindPoliciesForResource returns all policies whose compiled resources match the resource.
+func (m *RdbManager) FindPoliciesForResource(resource string) (Policies, error) {
+	return m.find("find_policies_for_resource", m.s.GetResourceCandidatesTerm(m.table, resource), "resource", resource)
+}
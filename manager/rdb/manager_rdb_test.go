@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -144,3 +145,35 @@ func Test_FilterPolicies(t *testing.T) {
 		t.Fatal("Data mismtach")
 	}
 }
+
+func Test_PolicySchemaNestedConditions(t *testing.T) {
+	p := &DefaultPolicy{
+		ID:        "123",
+		Subjects:  []string{"<.+>"},
+		Effect:    "allow",
+		Resources: []string{"rsc1"},
+		Actions:   []string{"GET"},
+		Syntax:    SyntaxRegexp,
+		Conditions: Conditions{
+			"access": &OrCondition{Conditions: Conditions{
+				"remoteIP": &CIDRCondition{CIDR: "10.0.0.0/8"},
+				"mfa": &NotCondition{Conditions: Conditions{
+					"mfa": &StringEqualCondition{Equals: "false"},
+				}},
+			}},
+		},
+	}
+
+	var s PolicySchema
+	if err := s.PopulateWithPolicy(p); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, got) {
+		t.Fatalf("expected %#v, got %#v", p, got)
+	}
+}
@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"encoding/json"
+	"regexp"
 	"strings"
 
 	. "github.com/d3sw/ladon"
@@ -24,6 +25,8 @@ type SchemaManager interface {
 	NewSchema() Schema
 	GetFilterFunc(subject, resource, action string) FilterFunc
 	GetRequestCandidatesTerm(table r.Term, subject, resource, action string) r.Term
+	GetSubjectCandidatesTerm(table r.Term, subjects []string) r.Term
+	GetResourceCandidatesTerm(table r.Term, resource string) r.Term
 	ProcessResult(r DBResult) chan *ProcessResult
 }
 
@@ -43,6 +46,7 @@ type PolicySchema struct {
 	Resources   resources       `json:"resources" gorethink:"resources"`
 	Actions     actions         `json:"actions" gorethink:"actions"`
 	Conditions  json.RawMessage `json:"conditions" gorethink:"conditions"`
+	Syntax      string          `json:"syntax" gorethink:"syntax"`
 }
 
 type subjects struct {
@@ -79,6 +83,7 @@ func (s *PolicySchema) GetPolicy() (Policy, error) {
 		Resources:   s.Resources.Raw,
 		Actions:     s.Actions.Raw,
 		Conditions:  cs,
+		Syntax:      s.Syntax,
 	}, nil
 }
 
@@ -89,6 +94,7 @@ func (s *PolicySchema) PopulateWithPolicy(p Policy) error {
 	}
 	s.ID = p.GetID()
 	s.Description = p.GetDescription()
+	s.Syntax = PolicySyntax(p)
 	s.Subjects.Raw = p.GetSubjects()
 	if err := s.compileSubject(); err != nil {
 		return err
@@ -108,7 +114,7 @@ func (s *PolicySchema) PopulateWithPolicy(p Policy) error {
 }
 
 func (s *PolicySchema) compileSubject() error {
-	res, err := compile(s.Subjects.Raw)
+	res, err := compile(s.Subjects.Raw, s.Syntax)
 	if err != nil {
 		return err
 	}
@@ -117,7 +123,7 @@ func (s *PolicySchema) compileSubject() error {
 }
 
 func (s *PolicySchema) compileResources() error {
-	res, err := compile(s.Resources.Raw)
+	res, err := compile(s.Resources.Raw, s.Syntax)
 	if err != nil {
 		return err
 	}
@@ -126,7 +132,7 @@ func (s *PolicySchema) compileResources() error {
 }
 
 func (s *PolicySchema) compileActions() error {
-	res, err := compile(s.Actions.Raw)
+	res, err := compile(s.Actions.Raw, s.Syntax)
 	if err != nil {
 		return err
 	}
@@ -134,10 +140,22 @@ func (s *PolicySchema) compileActions() error {
 	return nil
 }
 
-func compile(s []string) (string, error) {
+// compile joins the patterns to one regular expression. Globs are compiled without separator, so the
+// expression matches a superset of what the glob matcher accepts.
+func compile(s []string, syntax string) (string, error) {
+	// Variables are resolved when a request is evaluated, so they have to match any value here.
+	p := &DefaultPolicy{Syntax: syntax}
 	csubs := make([]string, len(s))
 	for i, s := range s {
-		if cs, err := compiler.CompileRegex(s, '<', '>'); err != nil {
+		var cs *regexp.Regexp
+		var err error
+		s = WildcardVariables(p, s)
+		if syntax == SyntaxGlob {
+			cs, err = compiler.CompileGlob(s, 0)
+		} else {
+			cs, err = compiler.CompileRegex(s, '<', '>')
+		}
+		if err != nil {
 			return "", err
 		} else {
 			csubs[i] = cs.String()
@@ -171,6 +189,22 @@ func (sm *PolicySchemaManager) GetRequestCandidatesTerm(table r.Term, sbj, res,
 	return table.Filter(sm.GetFilterFunc(sbj, res, act))
 }
 
+// GetSubjectCandidatesTerm returns term selecting all policies matching at least one of the subjects
+func (_ *PolicySchemaManager) GetSubjectCandidatesTerm(table r.Term, sbjs []string) r.Term {
+	return table.Filter(func(t r.Term) r.Term {
+		return r.Expr(sbjs).Contains(func(sbj r.Term) r.Term {
+			return sbj.Match(t.Field("subjects").Field("compiled"))
+		})
+	})
+}
+
+// GetResourceCandidatesTerm returns term selecting all policies matching the resource
+func (_ *PolicySchemaManager) GetResourceCandidatesTerm(table r.Term, res string) r.Term {
+	return table.Filter(func(t r.Term) r.Term {
+		return r.Expr(res).Match(t.Field("resources").Field("compiled"))
+	})
+}
+
 func (_ *PolicySchemaManager) ProcessResult(r DBResult) chan *ProcessResult {
 	schemaCh := make(chan *ProcessResult)
 
@@ -60,6 +60,28 @@ func (_mr *_MockManagerRecorder) FindRequestCandidates(arg0 interface{}) *gomock
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FindRequestCandidates", arg0)
 }
 
+func (_m *MockManager) FindPoliciesForResource(_param0 string) (ladon.Policies, error) {
+	ret := _m.ctrl.Call(_m, "FindPoliciesForResource", _param0)
+	ret0, _ := ret[0].(ladon.Policies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockManagerRecorder) FindPoliciesForResource(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FindPoliciesForResource", arg0)
+}
+
+func (_m *MockManager) FindPoliciesForSubject(_param0 string) (ladon.Policies, error) {
+	ret := _m.ctrl.Call(_m, "FindPoliciesForSubject", _param0)
+	ret0, _ := ret[0].(ladon.Policies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockManagerRecorder) FindPoliciesForSubject(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FindPoliciesForSubject", arg0)
+}
+
 func (_m *MockManager) Get(_param0 string) (ladon.Policy, error) {
 	ret := _m.ctrl.Call(_m, "Get", _param0)
 	ret0, _ := ret[0].(ladon.Policy)
@@ -100,7 +100,7 @@ var TestManagerPolicies = []*DefaultPolicy{
 		Actions:     []string{"disable"},
 		Conditions: Conditions{
 			"ip": &CIDRCondition{
-				CIDR: "1234",
+				CIDR: "1.2.3.4/32",
 			},
 			"owner": &EqualsSubjectCondition{},
 		},
@@ -114,7 +114,7 @@ var TestManagerPolicies = []*DefaultPolicy{
 		Actions:     []string{"view"},
 		Conditions: Conditions{
 			"ip": &CIDRCondition{
-				CIDR: "1234",
+				CIDR: "1.2.3.4/32",
 			},
 			"owner": &EqualsSubjectCondition{},
 		},
@@ -128,7 +128,7 @@ var TestManagerPolicies = []*DefaultPolicy{
 		Actions:     []string{"view"},
 		Conditions: Conditions{
 			"ip": &CIDRCondition{
-				CIDR: "1234",
+				CIDR: "1.2.3.4/32",
 			},
 			"owner": &EqualsSubjectCondition{},
 		},
@@ -349,3 +349,35 @@ func TestHelperCreateGetDelete(s Manager) func(t *testing.T) {
 
 	}
 }
+
+func TestHelperInvalidConditions(s Manager) func(t *testing.T) {
+	return func(t *testing.T) {
+		p := &DefaultPolicy{
+			ID:        uuid.New(),
+			Subjects:  []string{"peter"},
+			Actions:   []string{"view"},
+			Resources: []string{"articles"},
+			Effect:    AllowAccess,
+			Conditions: Conditions{
+				"remoteIP": &CIDRCondition{CIDR: "10.0.0.0/80"},
+			},
+		}
+		err := s.Create(p)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "remoteIP")
+
+		_, err = s.Get(p.GetID())
+		assert.Error(t, err)
+
+		p.Conditions["remoteIP"] = &CIDRCondition{CIDR: "10.0.0.0/8"}
+		require.NoError(t, s.Create(p))
+
+		p.Conditions["office"] = &OrCondition{Conditions: Conditions{"level": &NumericCompareCondition{Operator: "less"}}}
+		err = s.Update(p)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "office")
+		assert.Contains(t, err.Error(), "level")
+
+		require.NoError(t, s.Delete(p.GetID()))
+	}
+}
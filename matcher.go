@@ -1,7 +1,61 @@
 package ladon
 
-type matcher interface {
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// Matcher decides whether a needle, e.g. a request's resource, matches one of the patterns of a policy.
+type Matcher interface {
+	// Matches returns true if the needle matches one of the patterns in haystack.
 	Matches(p Policy, haystack []string, needle string) (matches bool, error error)
+
+	// Match returns which pattern in haystack matched the needle and what it captured. It returns nil if
+	// no pattern matched.
+	Match(p Policy, haystack []string, needle string) (*Match, error)
+}
+
+// Match describes which pattern matched a needle.
+type Match struct {
+	// Pattern is the haystack entry which matched.
+	Pattern string `json:"pattern"`
+
+	// Index is the position of Pattern in the haystack.
+	Index int `json:"index"`
+
+	// Captures are the values captured by the groups of the pattern's regular expressions, in order.
+	Captures []string `json:"captures,omitempty"`
+
+	// Named are the values captured by named groups, e.g. (?P<id>[0-9]+).
+	Named map[string]string `json:"named,omitempty"`
+}
+
+func newRegexpMatch(pattern string, index int, reg *regexp.Regexp, submatches []string) *Match {
+	m := &Match{
+		Pattern:  pattern,
+		Index:    index,
+		Captures: submatches[1:],
+	}
+	for i, name := range reg.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if m.Named == nil {
+			m.Named = map[string]string{}
+		}
+		m.Named[name] = submatches[i]
+	}
+	return m
+}
+
+// globFallback returns the fallback which matches the glob policy p or an error if there is none, so that glob
+// patterns are never compared as regular expressions or literally.
+func globFallback(fallback Matcher, p Policy) (Matcher, error) {
+	if fallback == nil {
+		return nil, errors.Errorf("policy %q uses the glob syntax, but there is no glob matcher", p.GetID())
+	}
+	return fallback, nil
 }
 
 var DefaultMatcher = NewRegexpMatcher(512)
@@ -0,0 +1,107 @@
+package ladon
+
+import (
+	"regexp"
+
+	"github.com/d3sw/ladon/compiler"
+	"github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+// NewGlobMatcher creates a GlobMatcher using separator as segment separator and caching up to size globs.
+// Policies which do not use the glob syntax are matched by DefaultMatcher.
+func NewGlobMatcher(separator byte, size int) *GlobMatcher {
+	if size <= 0 {
+		size = 512
+	}
+
+	cache, _ := lru.New(size)
+	return &GlobMatcher{
+		Cache:     cache,
+		Separator: separator,
+		Fallback:  DefaultMatcher,
+	}
+}
+
+// GlobMatcher matches glob patterns (see compiler.CompileGlob) of policies using the glob syntax, e.g.
+// "resources:articles:*" or "resources:{articles,comments}:**". Policies using another syntax are handed to
+// Fallback, so glob and regular expression policies can be stored in the same manager.
+type GlobMatcher struct {
+	*lru.Cache
+
+	// Separator is the segment separator which * and ? do not match. If it is 0, they match any character.
+	Separator byte
+
+	// Fallback matches policies which do not use the glob syntax.
+	Fallback Matcher
+}
+
+// Matches a needle with an array of globs and returns true if a match was found.
+func (m *GlobMatcher) Matches(p Policy, haystack []string, needle string) (bool, error) {
+	if PolicySyntax(p) != SyntaxGlob {
+		return m.Fallback.Matches(p, haystack, needle)
+	}
+
+	for _, h := range haystack {
+		// Globs without wildcards only match themselves, so they are not compiled.
+		if compiler.IsGlobLiteral(h) {
+			if h == needle {
+				return true, nil
+			}
+			continue
+		}
+
+		reg, err := m.compile(h)
+		if err != nil {
+			return false, err
+		}
+
+		if reg.MatchString(needle) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Match returns the first glob in haystack matching the needle together with the values matched by its
+// wildcards.
+func (m *GlobMatcher) Match(p Policy, haystack []string, needle string) (*Match, error) {
+	if PolicySyntax(p) != SyntaxGlob {
+		return m.Fallback.Match(p, haystack, needle)
+	}
+
+	for i, h := range haystack {
+		if compiler.IsGlobLiteral(h) {
+			if h == needle {
+				return &Match{Pattern: h, Index: i}, nil
+			}
+			continue
+		}
+
+		reg, err := m.compile(h)
+		if err != nil {
+			return nil, err
+		}
+
+		if sub := reg.FindStringSubmatch(needle); sub != nil {
+			return newRegexpMatch(h, i, reg, sub), nil
+		}
+	}
+	return nil, nil
+}
+
+// compile returns the cached regular expression of the glob or compiles it.
+func (m *GlobMatcher) compile(glob string) (*regexp.Regexp, error) {
+	if val, ok := m.Cache.Get(glob); ok {
+		if reg, ok := val.(*regexp.Regexp); ok {
+			return reg, nil
+		}
+	}
+
+	reg, err := compiler.CompileGlob(glob, m.Separator)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	m.Cache.Add(glob, reg)
+	return reg, nil
+}
@@ -0,0 +1,96 @@
+package ladon_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobMatcher(t *testing.T) {
+	// TrieMatcher and the default matcher hand glob policies to a GlobMatcher as well.
+	for _, m := range []Matcher{NewGlobMatcher(':', 64), NewTrieMatcher(64), nil} {
+		testGlobMatcher(t, m)
+	}
+}
+
+func testGlobMatcher(t *testing.T, m Matcher) {
+	warden := &Ladon{
+		Manager: NewMemoryManager(),
+		Matcher: m,
+	}
+	for _, pol := range []Policy{
+		&DefaultPolicy{
+			ID:        "glob",
+			Subjects:  []string{"users:{peter,max}"},
+			Actions:   []string{"{get,update}"},
+			Resources: []string{"articles:*", "comments:**"},
+			Effect:    AllowAccess,
+			Syntax:    SyntaxGlob,
+		},
+		&DefaultPolicy{
+			ID:        "regexp",
+			Subjects:  []string{"users:<zac|ken>"},
+			Actions:   []string{"get"},
+			Resources: []string{"articles:<[0-9]+>"},
+			Effect:    AllowAccess,
+		},
+	} {
+		require.NoError(t, warden.Manager.Create(pol))
+	}
+
+	for k, c := range []struct {
+		subject  string
+		action   string
+		resource string
+		pass     bool
+	}{
+		{subject: "users:peter", action: "get", resource: "articles:1", pass: true},
+		{subject: "users:max", action: "update", resource: "comments:1:2", pass: true},
+		{subject: "users:max", action: "update", resource: "articles:1:2", pass: false},
+		{subject: "users:max", action: "delete", resource: "articles:1", pass: false},
+		{subject: "users:zac", action: "get", resource: "articles:1", pass: true},
+		{subject: "users:zac", action: "get", resource: "articles:*", pass: false},
+		{subject: "users:{peter,max}", action: "get", resource: "articles:1", pass: false},
+	} {
+		t.Run(fmt.Sprintf("matcher=%T/case=%d", m, k), func(t *testing.T) {
+			err := warden.IsAllowed(&Request{Subjects: []string{c.subject}, Action: c.action, Resource: c.resource})
+			assert.Equal(t, c.pass, err == nil, "%v", err)
+		})
+	}
+}
+
+func TestPolicySyntax(t *testing.T) {
+	assert.Equal(t, SyntaxRegexp, PolicySyntax(&DefaultPolicy{}))
+	assert.Equal(t, SyntaxGlob, PolicySyntax(&DefaultPolicy{Syntax: SyntaxGlob}))
+	assert.Error(t, (&DefaultPolicy{Subjects: []string{"a"}, Effect: AllowAccess, Syntax: "foo"}).Validate())
+}
+
+func TestGlobMatcherMatch(t *testing.T) {
+	m := NewGlobMatcher(':', 16)
+	glob := &DefaultPolicy{Syntax: SyntaxGlob}
+
+	match, err := m.Match(glob, []string{"articles", "resources:*:{comments,likes}:**"}, "resources:1234:likes:a:b")
+	require.NoError(t, err)
+	assert.Equal(t, &Match{
+		Pattern:  "resources:*:{comments,likes}:**",
+		Index:    1,
+		Captures: []string{"1234", "a:b"},
+	}, match)
+
+	match, err = m.Match(glob, []string{"articles"}, "articles")
+	require.NoError(t, err)
+	assert.Equal(t, &Match{Pattern: "articles"}, match)
+
+	match, err = m.Match(glob, []string{"articles:*"}, "comments:1")
+	require.NoError(t, err)
+	assert.Nil(t, match)
+
+	// Policies using regular expressions are handed to the fallback.
+	match, err = m.Match(new(DefaultPolicy), []string{"articles:<[0-9]+>"}, "articles:12")
+	require.NoError(t, err)
+	assert.Equal(t, &Match{Pattern: "articles:<[0-9]+>", Captures: []string{"12"}}, match)
+}
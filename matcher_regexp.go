@@ -9,6 +9,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+// NewRegexpMatcher creates a RegexpMatcher caching up to size regular expressions. Policies using the glob
+// syntax are matched by a GlobMatcher using ':' as segment separator.
 func NewRegexpMatcher(size int) *RegexpMatcher {
 	if size <= 0 {
 		size = 512
@@ -16,15 +18,23 @@ func NewRegexpMatcher(size int) *RegexpMatcher {
 
 	// golang-lru only returns an error if the cache's size is 0. This, we can safely ignore this error.
 	cache, _ := lru.New(size)
-	return &RegexpMatcher{
+	m := &RegexpMatcher{
 		Cache: cache,
 	}
+
+	// The glob matcher hands all other policies back, so both syntaxes can be stored in the same manager.
+	globs, _ := lru.New(size)
+	m.Fallback = &GlobMatcher{Cache: globs, Separator: ':', Fallback: m}
+	return m
 }
 
 type RegexpMatcher struct {
 	*lru.Cache
 
 	C map[string]*regexp.Regexp
+
+	// Fallback matches policies using the glob syntax. If it is nil, such policies fail with an error.
+	Fallback Matcher
 }
 
 func (m *RegexpMatcher) get(pattern string) *regexp.Regexp {
@@ -43,6 +53,14 @@ func (m *RegexpMatcher) set(pattern string, reg *regexp.Regexp) {
 
 // Matches a needle with an array of regular expressions and returns true if a match was found.
 func (m *RegexpMatcher) Matches(p Policy, haystack []string, needle string) (bool, error) {
+	if PolicySyntax(p) == SyntaxGlob {
+		f, err := globFallback(m.Fallback, p)
+		if err != nil {
+			return false, err
+		}
+		return f.Matches(p, haystack, needle)
+	}
+
 	var reg *regexp.Regexp
 	var err error
 	for _, h := range haystack {
@@ -58,22 +76,59 @@ func (m *RegexpMatcher) Matches(p Policy, haystack []string, needle string) (boo
 			continue
 		}
 
-		if reg = m.get(h); reg != nil {
-			if reg.MatchString(needle) {
-				return true, nil
+		if reg, err = m.compile(p, h); err != nil {
+			return false, err
+		}
+
+		if reg.MatchString(needle) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Match returns the first pattern in haystack matching the needle together with the values captured by
+// the groups of its regular expression.
+func (m *RegexpMatcher) Match(p Policy, haystack []string, needle string) (*Match, error) {
+	if PolicySyntax(p) == SyntaxGlob {
+		f, err := globFallback(m.Fallback, p)
+		if err != nil {
+			return nil, err
+		}
+		return f.Match(p, haystack, needle)
+	}
+
+	for i, h := range haystack {
+		if strings.Count(h, string(p.GetStartDelimiter())) == 0 {
+			if h == needle {
+				return &Match{Pattern: h, Index: i}, nil
 			}
 			continue
 		}
 
-		reg, err = compiler.CompileRegex(h, p.GetStartDelimiter(), p.GetEndDelimiter())
+		reg, err := m.compile(p, h)
 		if err != nil {
-			return false, errors.WithStack(err)
+			return nil, err
 		}
 
-		m.set(h, reg)
-		if reg.MatchString(needle) {
-			return true, nil
+		if sub := reg.FindStringSubmatch(needle); sub != nil {
+			return newRegexpMatch(h, i, reg, sub), nil
 		}
 	}
-	return false, nil
+	return nil, nil
+}
+
+// compile returns the cached regular expression of the pattern or compiles it.
+func (m *RegexpMatcher) compile(p Policy, pattern string) (*regexp.Regexp, error) {
+	if reg := m.get(pattern); reg != nil {
+		return reg, nil
+	}
+
+	reg, err := compiler.CompileRegex(pattern, p.GetStartDelimiter(), p.GetEndDelimiter())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	m.set(pattern, reg)
+	return reg, nil
 }
@@ -0,0 +1,232 @@
+package ladon
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/d3sw/ladon/compiler"
+	"github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+// NewTrieMatcher creates a TrieMatcher caching the tries of up to size haystacks. Policies using the glob
+// syntax are matched by a GlobMatcher using ':' as segment separator.
+func NewTrieMatcher(size int) *TrieMatcher {
+	if size <= 0 {
+		size = 512
+	}
+
+	cache, _ := lru.New(size)
+	return &TrieMatcher{
+		Cache:    cache,
+		Fallback: NewGlobMatcher(':', size),
+	}
+}
+
+// TrieMatcher matches needles against patterns like "resources:articles:<.*>" without running a regular
+// expression for the literal part. Every pattern is split into its literal prefix, which is stored in a
+// prefix trie, and a regular expression tail. Tails which match anything are not evaluated at all. Policies
+// using the glob syntax are handed to Fallback.
+type TrieMatcher struct {
+	*lru.Cache
+
+	// Fallback matches policies using the glob syntax. If it is nil, such policies fail with an error.
+	Fallback Matcher
+}
+
+type trieNode struct {
+	children map[byte]*trieNode
+
+	// entries are the patterns whose literal prefix ends at this node.
+	entries []*trieEntry
+}
+
+type trieEntry struct {
+	pattern string
+	index   int
+
+	// tail is the regular expression following the literal prefix. It is nil for literal patterns.
+	tail *regexp.Regexp
+
+	// any is true if the tail matches any remainder, i.e. it is <.*>.
+	any bool
+}
+
+// match returns the values captured by the entry if it matches rest, which is the part of the needle
+// following the entry's literal prefix, or nil otherwise.
+func (e *trieEntry) match(rest string, captures bool) []string {
+	switch {
+	case e.any:
+		// "." does not match new lines, so neither must the wildcard.
+		if strings.IndexByte(rest, '\n') >= 0 {
+			return nil
+		}
+		return []string{rest}
+	case e.tail != nil:
+		if !captures {
+			if e.tail.MatchString(rest) {
+				return []string{}
+			}
+			return nil
+		}
+		return e.tail.FindStringSubmatch(rest)
+	case rest == "":
+		return []string{}
+	default:
+		return nil
+	}
+}
+
+func (n *trieNode) child(c byte) *trieNode {
+	if n.children == nil {
+		n.children = map[byte]*trieNode{}
+	}
+	next, ok := n.children[c]
+	if !ok {
+		next = &trieNode{}
+		n.children[c] = next
+	}
+	return next
+}
+
+func newTrie(haystack []string, start, end byte) (*trieNode, error) {
+	root := &trieNode{}
+	wildcard := string(start) + ".*" + string(end)
+	for k, h := range haystack {
+		i := strings.IndexByte(h, start)
+		prefix := h
+		if i >= 0 {
+			prefix = h[:i]
+		}
+
+		n := root
+		for j := 0; j < len(prefix); j++ {
+			n = n.child(prefix[j])
+		}
+
+		e := &trieEntry{pattern: h, index: k}
+		n.entries = append(n.entries, e)
+
+		// Literal patterns are matched by the trie alone.
+		if i < 0 {
+			continue
+		}
+
+		tail := h[i:]
+		reg, err := compiler.CompileRegex(tail, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		if tail == wildcard {
+			e.any = true
+			continue
+		}
+		e.tail = reg
+	}
+	return root, nil
+}
+
+// walk calls fn for every entry whose literal prefix is a prefix of the needle, together with the rest of
+// the needle. It stops as soon as fn returns false.
+func (n *trieNode) walk(needle string, fn func(e *trieEntry, rest string) bool) {
+	for i := 0; n != nil; i++ {
+		for _, e := range n.entries {
+			if !fn(e, needle[i:]) {
+				return
+			}
+		}
+
+		if i == len(needle) {
+			return
+		}
+		n = n.children[needle[i]]
+	}
+}
+
+func (n *trieNode) matches(needle string) bool {
+	var found bool
+	n.walk(needle, func(e *trieEntry, rest string) bool {
+		found = e.match(rest, false) != nil
+		return !found
+	})
+	return found
+}
+
+// match returns the matching entry with the lowest index, so that the result is the same as
+// iterating over the haystack.
+func (n *trieNode) match(needle string) *Match {
+	var best *Match
+	n.walk(needle, func(e *trieEntry, rest string) bool {
+		if best != nil && best.Index < e.index {
+			return true
+		}
+
+		sub := e.match(rest, true)
+		switch {
+		case sub == nil:
+			return true
+		case e.tail != nil:
+			best = newRegexpMatch(e.pattern, e.index, e.tail, sub)
+		case e.any:
+			best = &Match{Pattern: e.pattern, Index: e.index, Captures: sub}
+		default:
+			best = &Match{Pattern: e.pattern, Index: e.index}
+		}
+		return true
+	})
+	return best
+}
+
+// Matches a needle with an array of patterns and returns true if a match was found.
+func (m *TrieMatcher) Matches(p Policy, haystack []string, needle string) (bool, error) {
+	if PolicySyntax(p) == SyntaxGlob {
+		f, err := globFallback(m.Fallback, p)
+		if err != nil {
+			return false, err
+		}
+		return f.Matches(p, haystack, needle)
+	}
+
+	t, err := m.trie(p, haystack)
+	if err != nil {
+		return false, err
+	}
+	return t.matches(needle), nil
+}
+
+// Match returns the first pattern in haystack matching the needle together with the values captured by
+// the groups of its regular expression.
+func (m *TrieMatcher) Match(p Policy, haystack []string, needle string) (*Match, error) {
+	if PolicySyntax(p) == SyntaxGlob {
+		f, err := globFallback(m.Fallback, p)
+		if err != nil {
+			return nil, err
+		}
+		return f.Match(p, haystack, needle)
+	}
+
+	t, err := m.trie(p, haystack)
+	if err != nil {
+		return nil, err
+	}
+	return t.match(needle), nil
+}
+
+// trie returns the cached trie of the haystack or builds it.
+func (m *TrieMatcher) trie(p Policy, haystack []string) (*trieNode, error) {
+	key := string(p.GetStartDelimiter()) + string(p.GetEndDelimiter()) + strings.Join(haystack, "\x00")
+
+	if val, ok := m.Cache.Get(key); ok {
+		if t, ok := val.(*trieNode); ok {
+			return t, nil
+		}
+	}
+
+	t, err := newTrie(haystack, p.GetStartDelimiter(), p.GetEndDelimiter())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	m.Cache.Add(key, t)
+	return t, nil
+}
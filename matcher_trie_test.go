@@ -0,0 +1,137 @@
+package ladon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieMatcher(t *testing.T) {
+	p := new(DefaultPolicy)
+	trie := NewTrieMatcher(16)
+	reg := NewRegexpMatcher(16)
+
+	for _, c := range []struct {
+		haystack []string
+		needle   string
+		pass     bool
+	}{
+		{haystack: []string{"resources:articles:<.*>"}, needle: "resources:articles:1234", pass: true},
+		{haystack: []string{"resources:articles:<.*>"}, needle: "resources:articles:", pass: true},
+		{haystack: []string{"resources:articles:<.*>"}, needle: "resources:article", pass: false},
+		{haystack: []string{"resources:articles:<.*>"}, needle: "resources:articles:1\n2", pass: false},
+		{haystack: []string{"resources:articles:<[0-9]+>"}, needle: "resources:articles:1234", pass: true},
+		{haystack: []string{"resources:articles:<[0-9]+>"}, needle: "resources:articles:12a", pass: false},
+		{haystack: []string{"resources:articles:<[0-9]+>:comments"}, needle: "resources:articles:12:comments", pass: true},
+		{haystack: []string{"resources:articles:<[0-9]+>:comments"}, needle: "resources:articles:12:comment", pass: false},
+		{haystack: []string{"<zac|peter>"}, needle: "peter", pass: true},
+		{haystack: []string{"<zac|peter>"}, needle: "max", pass: false},
+		{haystack: []string{"<.*>"}, needle: "", pass: true},
+		{haystack: []string{"max", "peter", "pet<.+>"}, needle: "pet", pass: false},
+		{haystack: []string{"max", "peter", "pet<.+>"}, needle: "peter", pass: true},
+		{haystack: []string{"max", "peter", "pet<.+>"}, needle: "petra", pass: true},
+		{haystack: []string{"max", "peter", "pet<.+>"}, needle: "ma", pass: false},
+		{haystack: []string{"a.b"}, needle: "axb", pass: false},
+		{haystack: []string{"a.<b>"}, needle: "axb", pass: false},
+		{haystack: []string{}, needle: "", pass: false},
+	} {
+		for k, m := range []Matcher{trie, reg} {
+			// run twice to hit the cache
+			for i := 0; i < 2; i++ {
+				ok, err := m.Matches(p, c.haystack, c.needle)
+				assert.NoError(t, err)
+				assert.Equal(t, c.pass, ok, "matcher=%d haystack=%v needle=%q", k, c.haystack, c.needle)
+			}
+		}
+	}
+
+	_, err := trie.Matches(p, []string{"foo:<[0-9]+"}, "foo:1")
+	assert.Error(t, err)
+}
+
+func TestMatcherMatch(t *testing.T) {
+	p := new(DefaultPolicy)
+	trie := NewTrieMatcher(16)
+	reg := NewRegexpMatcher(16)
+
+	for _, c := range []struct {
+		haystack []string
+		needle   string
+		expected *Match
+	}{
+		{
+			haystack: []string{"resources:articles:<[0-9]+>"},
+			needle:   "resources:articles:1234",
+			expected: &Match{Pattern: "resources:articles:<[0-9]+>", Captures: []string{"1234"}},
+		},
+		{
+			haystack: []string{"resources:articles:<.*>"},
+			needle:   "resources:articles:1234",
+			expected: &Match{Pattern: "resources:articles:<.*>", Captures: []string{"1234"}},
+		},
+		{
+			haystack: []string{"resources:<(?P<kind>[a-z]+)>:<(?P<id>[0-9]+)>"},
+			needle:   "resources:articles:12",
+			expected: &Match{
+				Pattern:  "resources:<(?P<kind>[a-z]+)>:<(?P<id>[0-9]+)>",
+				Captures: []string{"articles", "articles", "12", "12"},
+				Named:    map[string]string{"kind": "articles", "id": "12"},
+			},
+		},
+		{
+			haystack: []string{"pet<.+>", "peter"},
+			needle:   "peter",
+			expected: &Match{Pattern: "pet<.+>", Captures: []string{"er"}},
+		},
+		{
+			haystack: []string{"peter", "pet<.+>"},
+			needle:   "peter",
+			expected: &Match{Pattern: "peter"},
+		},
+		{
+			haystack: []string{"max", "pet<.+>"},
+			needle:   "pet",
+		},
+	} {
+		for k, m := range []Matcher{trie, reg} {
+			match, err := m.Match(p, c.haystack, c.needle)
+			assert.NoError(t, err)
+			if c.expected != nil {
+				c.expected.Index = indexOf(c.haystack, c.expected.Pattern)
+			}
+			assert.Equal(t, c.expected, match, "matcher=%d haystack=%v needle=%q", k, c.haystack, c.needle)
+		}
+	}
+}
+
+func indexOf(haystack []string, pattern string) int {
+	for k, h := range haystack {
+		if h == pattern {
+			return k
+		}
+	}
+	return -1
+}
+
+func TestTrieMatcherGlobPolicies(t *testing.T) {
+	glob := &DefaultPolicy{Syntax: SyntaxGlob}
+	trie := NewTrieMatcher(16)
+
+	ok, err := trie.Matches(glob, []string{"users:{max,peter}"}, "users:max")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = trie.Matches(glob, []string{"a:*"}, "a:b:c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	m, err := trie.Match(glob, []string{"a", "a:*"}, "a:b")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, 1, m.Index)
+
+	// Without a fallback glob policies are rejected instead of being compared literally.
+	_, err = (&TrieMatcher{Cache: trie.Cache}).Matches(glob, []string{"a:*"}, "a:*")
+	assert.Error(t, err)
+}
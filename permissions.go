@@ -0,0 +1,178 @@
+package ladon
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Permission is an action and resource pattern a subject is allowed or denied.
+type Permission struct {
+	// Action is the action pattern.
+	Action string `json:"action"`
+
+	// Resource is the resource pattern.
+	Resource string `json:"resource"`
+
+	// Effect is either 'allow' or 'deny'.
+	Effect string `json:"effect"`
+
+	// Policies are the IDs of the policies granting or denying the permission.
+	Policies []string `json:"policies"`
+
+	// Conditional is true if every policy granting or denying the permission has conditions, i.e. the
+	// permission only applies if the request's context fulfills them.
+	Conditional bool `json:"conditional"`
+}
+
+type permission struct {
+	*Permission
+	policy Policy
+}
+
+// Permissions returns the action and resource patterns the subjects are allowed or denied. ${subject} in
+// patterns is replaced with the subjects, patterns with other variables are conditional. Allow patterns
+// which are fully covered by an unconditional deny pattern are removed. Because regular expressions can
+// not be compared in general, an allow pattern is only considered covered if the deny pattern is identical
+// or matches the allow pattern literally.
+func (l *Ladon) Permissions(subjects []string) ([]Permission, error) {
+	var policies Policies
+	seen := map[string]bool{}
+	for _, subject := range subjects {
+		ps, err := l.Manager.FindPoliciesForSubject(subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			if seen[p.GetID()] {
+				continue
+			}
+			seen[p.GetID()] = true
+			policies = append(policies, p)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].GetID() < policies[j].GetID()
+	})
+
+	var perms []*permission
+	index := map[string]*permission{}
+	r := &Request{Subjects: subjects}
+	for _, p := range policies {
+		// The manager might return a superset, so make sure the policy actually applies to the subjects.
+		if ok, err := l.checkSubjects(p, r); err != nil {
+			return nil, errors.WithStack(err)
+		} else if !ok {
+			continue
+		}
+
+		actions, conditionalActions := permissionPatterns(p, p.GetActions(), r)
+		resources, conditionalResources := permissionPatterns(p, p.GetResources(), r)
+		for i, action := range actions {
+			for j, resource := range resources {
+				conditional := len(p.GetConditions()) > 0 || conditionalActions[i] || conditionalResources[j]
+				key := strings.Join([]string{p.GetEffect(), action, resource}, "\x00")
+				if perm, ok := index[key]; ok {
+					perm.Policies = append(perm.Policies, p.GetID())
+					perm.Conditional = perm.Conditional && conditional
+					continue
+				}
+
+				perm := &permission{
+					Permission: &Permission{
+						Action:      action,
+						Resource:    resource,
+						Effect:      p.GetEffect(),
+						Policies:    []string{p.GetID()},
+						Conditional: conditional,
+					},
+					policy: p,
+				}
+				index[key] = perm
+				perms = append(perms, perm)
+			}
+		}
+	}
+
+	var result []Permission
+	for _, perm := range perms {
+		if perm.Effect != DenyAccess {
+			denied, err := l.overriddenByDeny(perm, perms)
+			if err != nil {
+				return nil, err
+			} else if denied {
+				continue
+			}
+		}
+		result = append(result, *perm.Permission)
+	}
+
+	return result, nil
+}
+
+// permissionPatterns resolves ${subject} in the patterns with the subjects of the request. Patterns referring
+// to other variables depend on the request's context, so they are returned as they are and flagged as
+// conditional.
+func permissionPatterns(p Policy, patterns []string, r *Request) ([]string, []bool) {
+	var resolved []string
+	var conditional []bool
+	for _, pattern := range patterns {
+		if !HasVariables(pattern) {
+			resolved = append(resolved, pattern)
+			conditional = append(conditional, false)
+			continue
+		}
+
+		rs := ResolveVariables(p, []string{pattern}, r)
+		if len(rs) == 0 {
+			resolved = append(resolved, pattern)
+			conditional = append(conditional, true)
+			continue
+		}
+		for _, rp := range rs {
+			resolved = append(resolved, rp)
+			conditional = append(conditional, false)
+		}
+	}
+	return resolved, conditional
+}
+
+func (l *Ladon) overriddenByDeny(allow *permission, perms []*permission) (bool, error) {
+	for _, deny := range perms {
+		if deny.Effect != DenyAccess || deny.Conditional {
+			continue
+		}
+
+		if ok, err := l.covers(deny, deny.Action, allow, allow.Action); err != nil {
+			return false, err
+		} else if !ok {
+			continue
+		}
+
+		if ok, err := l.covers(deny, deny.Resource, allow, allow.Resource); err != nil {
+			return false, err
+		} else if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// covers returns true if every value matching target also matches pattern.
+func (l *Ladon) covers(pp *permission, pattern string, tp *permission, target string) (bool, error) {
+	if pattern == target {
+		return true, nil
+	}
+
+	// The target is a pattern itself and thus can not be matched literally.
+	if !IsLiteral(tp.policy, target) {
+		return false, nil
+	}
+
+	ok, err := l.matcher().Matches(pp.policy, []string{pattern}, target)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return ok, nil
+}
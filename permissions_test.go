@@ -0,0 +1,93 @@
+package ladon_test
+
+import (
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLadonPermissions(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range []Policy{
+		&DefaultPolicy{
+			ID:        "1",
+			Subjects:  []string{"ken", "<zac|max>"},
+			Actions:   []string{"get", "update"},
+			Resources: []string{"articles:1", "articles:<[0-9]+>"},
+			Effect:    AllowAccess,
+		},
+		&DefaultPolicy{
+			ID:        "2",
+			Subjects:  []string{"ken"},
+			Actions:   []string{"<.*>"},
+			Resources: []string{"articles:1"},
+			Effect:    DenyAccess,
+		},
+		&DefaultPolicy{
+			ID:         "3",
+			Subjects:   []string{"ken"},
+			Actions:    []string{"delete"},
+			Resources:  []string{"articles:<[0-9]+>"},
+			Effect:     AllowAccess,
+			Conditions: Conditions{"owner": &EqualsSubjectCondition{}},
+		},
+		&DefaultPolicy{
+			ID:        "4",
+			Subjects:  []string{"peter"},
+			Actions:   []string{"get"},
+			Resources: []string{"<.*>"},
+			Effect:    AllowAccess,
+		},
+	} {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	perms, err := warden.Permissions([]string{"ken"})
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{
+		{Action: "get", Resource: "articles:<[0-9]+>", Effect: AllowAccess, Policies: []string{"1"}},
+		{Action: "update", Resource: "articles:<[0-9]+>", Effect: AllowAccess, Policies: []string{"1"}},
+		{Action: "<.*>", Resource: "articles:1", Effect: DenyAccess, Policies: []string{"2"}},
+		{Action: "delete", Resource: "articles:<[0-9]+>", Effect: AllowAccess, Policies: []string{"3"}, Conditional: true},
+	}, perms)
+
+	perms, err = warden.Permissions([]string{"zac", "peter"})
+	require.NoError(t, err)
+	assert.Len(t, perms, 5)
+
+	perms, err = warden.Permissions([]string{"nobody"})
+	require.NoError(t, err)
+	assert.Empty(t, perms)
+}
+
+func TestLadonPermissionsVariables(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range []Policy{
+		&DefaultPolicy{
+			ID:        "home",
+			Subjects:  []string{"<.*>"},
+			Actions:   []string{"get"},
+			Resources: []string{"files:home:${subject}"},
+			Effect:    AllowAccess,
+		},
+		&DefaultPolicy{
+			ID:        "tenant",
+			Subjects:  []string{"ken"},
+			Actions:   []string{"get"},
+			Resources: []string{"files:${context.tenant}:<.*>"},
+			Effect:    AllowAccess,
+		},
+	} {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	perms, err := warden.Permissions([]string{"ken"})
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{
+		{Action: "get", Resource: "files:home:ken", Effect: AllowAccess, Policies: []string{"home"}},
+		{Action: "get", Resource: "files:${context.tenant}:<.*>", Effect: AllowAccess, Policies: []string{"tenant"}, Conditional: true},
+	}, perms)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/d3sw/ladon/compiler"
 	"github.com/pkg/errors"
 )
 
@@ -48,6 +49,32 @@ type Policy interface {
 	GetEndDelimiter() byte
 }
 
+// SyntaxPolicy is implemented by policies which declare the syntax of their subjects, resources and actions.
+type SyntaxPolicy interface {
+	// GetSyntax returns the policies syntax which might be 'regexp' or 'glob'.
+	GetSyntax() string
+}
+
+// PolicySyntax returns the syntax of the policy. Policies not implementing SyntaxPolicy use regular expressions.
+func PolicySyntax(p Policy) string {
+	if sp, ok := p.(SyntaxPolicy); ok && sp.GetSyntax() != "" {
+		return sp.GetSyntax()
+	}
+	return SyntaxRegexp
+}
+
+// IsLiteral returns true if the pattern of the policy contains no wildcards, regular expressions or
+// variables and thus only matches itself.
+func IsLiteral(p Policy, pattern string) bool {
+	if HasVariables(pattern) {
+		return false
+	}
+	if PolicySyntax(p) == SyntaxGlob {
+		return compiler.IsGlobLiteral(pattern)
+	}
+	return strings.IndexByte(pattern, p.GetStartDelimiter()) < 0
+}
+
 // swagger:response Policies
 type DefaultPolicies struct {
 	// in: body
@@ -65,6 +92,7 @@ type DefaultPolicy struct {
 	Resources   []string   `json:"resources" gorethink:"resources"`
 	Actions     []string   `json:"actions" gorethink:"actions"`
 	Conditions  Conditions `json:"conditions" gorethink:"conditions"`
+	Syntax      string     `json:"syntax,omitempty" gorethink:"syntax"`
 }
 
 // UnmarshalJSON overwrite own policy with values of the given in policy in JSON format
@@ -77,6 +105,7 @@ func (p *DefaultPolicy) UnmarshalJSON(data []byte) error {
 		Resources   []string   `json:"resources" gorethink:"resources"`
 		Actions     []string   `json:"actions" gorethink:"actions"`
 		Conditions  Conditions `json:"conditions" gorethink:"conditions"`
+		Syntax      string     `json:"syntax,omitempty" gorethink:"syntax"`
 	}{
 		Conditions: Conditions{},
 	}
@@ -93,6 +122,7 @@ func (p *DefaultPolicy) UnmarshalJSON(data []byte) error {
 		Resources:   pol.Resources,
 		Actions:     pol.Actions,
 		Conditions:  pol.Conditions,
+		Syntax:      pol.Syntax,
 	}
 	return nil
 }
@@ -137,6 +167,14 @@ func (p *DefaultPolicy) GetConditions() Conditions {
 	return p.Conditions
 }
 
+// GetSyntax returns the policies syntax which might be 'regexp' or 'glob'.
+func (p *DefaultPolicy) GetSyntax() string {
+	if p.Syntax == "" {
+		return SyntaxRegexp
+	}
+	return p.Syntax
+}
+
 // GetEndDelimiter returns the delimiter which identifies the end of a regular expression.
 func (p *DefaultPolicy) GetEndDelimiter() byte {
 	return '>'
@@ -205,6 +243,12 @@ func (p *DefaultPolicy) Validate() error {
 	if err == nil {
 		err = p.ValidateEffect()
 	}
+	if err == nil {
+		err = p.ValidateSyntax()
+	}
+	if err == nil {
+		err = p.Conditions.Validate()
+	}
 	return err
 }
 
@@ -232,6 +276,14 @@ func (p *DefaultPolicy) ValidateEffect() error {
 	return nil
 }
 
+// ValidateSyntax validates the syntax
+func (p *DefaultPolicy) ValidateSyntax() error {
+	if p.Syntax != "" && p.Syntax != SyntaxRegexp && p.Syntax != SyntaxGlob {
+		return fmt.Errorf("unsupported syntax: %s", p.Syntax)
+	}
+	return nil
+}
+
 func (p *DefaultPolicy) CheckID() (string, string, error) {
 	id := p.GetID()
 	parts := strings.Split(id, ".")
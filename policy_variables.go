@@ -0,0 +1,166 @@
+package ladon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/d3sw/ladon/compiler"
+)
+
+// HasVariables returns true if the pattern contains substitution variables like ${subject}.
+func HasVariables(pattern string) bool {
+	return strings.Contains(pattern, "${")
+}
+
+// ResolveVariables substitutes the variables in the patterns with values of the request. ${subject} is replaced
+// with the request's subjects, producing one pattern per subject, and ${context.<key>} with the request's context
+// value of key, which must be a string, a number or a bool. Values are escaped, so they only match themselves
+// and can not inject a pattern. Patterns referring to values the request does not have are dropped, because
+// they can not match. So are values containing the policy's delimiters outside of them, because escaping
+// them would add a group and shift the numbering of the captured variables.
+func ResolveVariables(p Policy, patterns []string, r *Request) []string {
+	var resolved []string
+	for i, pattern := range patterns {
+		if !HasVariables(pattern) {
+			if resolved != nil {
+				resolved = append(resolved, pattern)
+			}
+			continue
+		}
+
+		if resolved == nil {
+			resolved = append([]string{}, patterns[:i]...)
+		}
+		resolved = append(resolved, substituteVariables(p, pattern, func(name string, nested bool) []string {
+			var values []string
+			for _, v := range requestVariable(r, name) {
+				if q, ok := quoteVariable(p, v, nested); ok {
+					values = append(values, q)
+				}
+			}
+			return values
+		})...)
+	}
+
+	if resolved == nil {
+		return patterns
+	}
+	return resolved
+}
+
+// WildcardVariables replaces the variables in the pattern with wildcards matching any value. Managers use
+// it to look up policies whose patterns contain variables.
+func WildcardVariables(p Policy, pattern string) string {
+	if !HasVariables(pattern) {
+		return pattern
+	}
+
+	resolved := substituteVariables(p, pattern, func(_ string, nested bool) []string {
+		switch {
+		case PolicySyntax(p) == SyntaxGlob:
+			return []string{"**"}
+		case nested:
+			return []string{".*"}
+		default:
+			return []string{string(p.GetStartDelimiter()) + ".*" + string(p.GetEndDelimiter())}
+		}
+	})
+	return resolved[0]
+}
+
+func requestVariable(r *Request, name string) []string {
+	switch {
+	case name == "subject":
+		return append([]string{}, r.Subjects...)
+	case strings.HasPrefix(name, "context."):
+		switch v := r.Context[strings.TrimPrefix(name, "context.")].(type) {
+		case string:
+			return []string{v}
+		case bool, int, int32, int64, uint, uint32, uint64, float32, float64, json.Number:
+			return []string{fmt.Sprint(v)}
+		}
+	}
+	return nil
+}
+
+// substituteVariables replaces every variable in the pattern with each of the values returned for it and
+// returns all combinations. nested is true if the variable is part of a regular expression, i.e. enclosed
+// in the policy's delimiters. If there are no values for a variable, nil is returned.
+func substituteVariables(p Policy, pattern string, values func(name string, nested bool) []string) []string {
+	glob := PolicySyntax(p) == SyntaxGlob
+	results := []string{""}
+	depth := 0
+	for {
+		i := strings.Index(pattern, "${")
+		j := -1
+		if i >= 0 {
+			j = strings.IndexByte(pattern[i:], '}')
+		}
+		if j < 0 {
+			for k := range results {
+				results[k] += pattern
+			}
+			return results
+		}
+
+		literal := pattern[:i]
+		if !glob {
+			for k := 0; k < len(literal); k++ {
+				switch literal[k] {
+				case p.GetStartDelimiter():
+					depth++
+				case p.GetEndDelimiter():
+					depth--
+				}
+			}
+		}
+
+		vs := values(pattern[i+2:i+j], depth > 0)
+		if len(vs) == 0 {
+			return nil
+		}
+
+		next := make([]string, 0, len(results)*len(vs))
+		for _, r := range results {
+			for _, v := range vs {
+				next = append(next, r+literal+v)
+			}
+		}
+		results = next
+		pattern = pattern[i+j+1:]
+	}
+}
+
+// quoteVariable escapes the value so that it only matches itself. It returns false if the value contains the
+// policy's delimiters and is not part of a regular expression, because it can not be escaped there.
+func quoteVariable(p Policy, value string, nested bool) (string, bool) {
+	var b bytes.Buffer
+	if PolicySyntax(p) == SyntaxGlob {
+		for i := 0; i < len(value); i++ {
+			if strings.IndexByte(compiler.GlobChars+",", value[i]) >= 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(value[i])
+		}
+		return b.String(), true
+	}
+
+	start, end := p.GetStartDelimiter(), p.GetEndDelimiter()
+	if !nested {
+		// Outside of the delimiters the value is matched literally anyway.
+		return value, strings.IndexByte(value, start) < 0 && strings.IndexByte(value, end) < 0
+	}
+
+	// Delimiters are written as hex escapes, because the compiler does not understand escaped delimiters.
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c == start || c == end {
+			fmt.Fprintf(&b, `\x%02x`, c)
+		} else {
+			b.WriteString(regexp.QuoteMeta(value[i : i+1]))
+		}
+	}
+	return b.String(), true
+}
@@ -0,0 +1,86 @@
+package ladon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVariables(t *testing.T) {
+	reg := &DefaultPolicy{}
+	glob := &DefaultPolicy{Syntax: SyntaxGlob}
+	r := &Request{
+		Subjects: []string{"peter", "<.*>"},
+		Context:  Context{"tenant": "acme", "level": float64(3), "list": []string{"a"}},
+	}
+
+	for k, c := range []struct {
+		p        Policy
+		patterns []string
+		expected []string
+	}{
+		{p: reg, patterns: []string{"articles:<.*>"}, expected: []string{"articles:<.*>"}},
+		{p: reg, patterns: []string{"home:${subject}:<.*>"}, expected: []string{"home:peter:<.*>"}},
+		{p: reg, patterns: []string{"<${context.tenant}|admin>:${context.level}"}, expected: []string{"<acme|admin>:3"}},
+		{p: reg, patterns: []string{"a", "${context.list}", "${context.missing}", "b"}, expected: []string{"a", "b"}},
+		{p: reg, patterns: []string{"${context.tenant}:${subject}"}, expected: []string{"acme:peter"}},
+		{p: reg, patterns: []string{"<${subject}|admin>"}, expected: []string{"<peter|admin>", `<\x3c\.\*\x3e|admin>`}},
+		{p: glob, patterns: []string{"home:${subject}:*"}, expected: []string{"home:peter:*", `home:<.\*>:*`}},
+	} {
+		assert.Equal(t, c.expected, ResolveVariables(c.p, c.patterns, r), "case %d", k)
+	}
+
+	assert.Equal(t, "home:<.*>:<.*>", WildcardVariables(reg, "home:${subject}:<.*>"))
+	assert.Equal(t, "<.*|admin>", WildcardVariables(reg, "<${context.tenant}|admin>"))
+	assert.Equal(t, "home:**:*", WildcardVariables(glob, "home:${subject}:*"))
+	assert.False(t, IsLiteral(reg, "home:${subject}"))
+}
+
+func TestVariablesCannotInjectPatterns(t *testing.T) {
+	p := &DefaultPolicy{
+		ID:        "home",
+		Subjects:  []string{"<.*>"},
+		Actions:   []string{"get"},
+		Resources: []string{"home:${subject}:<.*>", "tenants:<${context.tenant}>"},
+		Effect:    AllowAccess,
+	}
+
+	for _, m := range []Matcher{NewRegexpMatcher(16), NewTrieMatcher(16)} {
+		l := &Ladon{Matcher: m}
+		for k, c := range []struct {
+			r       *Request
+			allowed bool
+		}{
+			{r: &Request{Subjects: []string{"peter"}, Action: "get", Resource: "home:peter:notes"}, allowed: true},
+			{r: &Request{Subjects: []string{"peter"}, Action: "get", Resource: "home:max:notes"}, allowed: false},
+			{r: &Request{Subjects: []string{"<.*>"}, Action: "get", Resource: "home:max:notes"}, allowed: false},
+			{r: &Request{Subjects: []string{"<.*>"}, Action: "get", Resource: "home:<.*>:notes"}, allowed: false},
+			{r: &Request{Subjects: []string{"pe.er"}, Action: "get", Resource: "home:peter:notes"}, allowed: false},
+			{r: &Request{Subjects: []string{"x"}, Action: "get", Resource: "tenants:acme", Context: Context{"tenant": "acme"}}, allowed: true},
+			{r: &Request{Subjects: []string{"x"}, Action: "get", Resource: "tenants:acme", Context: Context{"tenant": ".*"}}, allowed: false},
+			{r: &Request{Subjects: []string{"x"}, Action: "get", Resource: "tenants:acme"}, allowed: false},
+		} {
+			_, err := l.doPoliciesAllow(c.r, Policies{p})
+			assert.Equal(t, c.allowed, err == nil, "case %d: %v", k, err)
+		}
+	}
+}
+
+func TestVariablesDoNotShiftCaptures(t *testing.T) {
+	p := &DefaultPolicy{
+		ID:         "home",
+		Subjects:   []string{"<.*>"},
+		Actions:    []string{"get"},
+		Resources:  []string{"home:<${subject}>:<.*>"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"owner": &StringEqualCondition{Equals: "x"}},
+	}
+
+	for _, m := range []Matcher{NewRegexpMatcher(16), NewTrieMatcher(16)} {
+		l := &Ladon{Matcher: m}
+		vr, err := l.withVariables(p, &Request{Subjects: []string{"<x>"}, Action: "get", Resource: "home:<x>:notes"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<x>", vr.Variables["resource.1"])
+		assert.Equal(t, "notes", vr.Variables["resource.2"])
+	}
+}
@@ -0,0 +1,65 @@
+package ladon
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Variables are the values captured while matching a request against a policy. The groups captured by the
+// policy's action, subject and resource patterns are available as "<field>.<n>", where n counts the groups
+// starting at 1, and named groups additionally as "<field>.<name>", e.g. "resource.1" or "resource.id"
+// for the pattern "resources:users:<(?P<id>.*)>".
+type Variables map[string]string
+
+// capture adds the values captured by match using field as prefix.
+func (v Variables) capture(field string, match *Match) {
+	if match == nil {
+		return
+	}
+	for i, c := range match.Captures {
+		v[field+"."+strconv.Itoa(i+1)] = c
+	}
+	for name, c := range match.Named {
+		v[field+"."+name] = c
+	}
+}
+
+// withVariables returns a copy of the request whose Variables hold the values captured by the policy's
+// patterns, so that conditions can refer to them. Variables set by the caller are kept unless a capture
+// has the same name. The request is returned as is if the policy has no conditions.
+func (l *Ladon) withVariables(p Policy, r *Request) (*Request, error) {
+	if len(p.GetConditions()) == 0 {
+		return r, nil
+	}
+
+	vars := Variables{}
+	for k, v := range r.Variables {
+		vars[k] = v
+	}
+
+	match, err := l.matcher().Match(p, ResolveVariables(p, p.GetActions(), r), r.Action)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vars.capture("action", match)
+
+	subjects := ResolveVariables(p, p.GetSubjects(), r)
+	for _, sub := range r.Subjects {
+		if match, err = l.matcher().Match(p, subjects, sub); err != nil {
+			return nil, errors.WithStack(err)
+		} else if match != nil {
+			vars.capture("subject", match)
+			break
+		}
+	}
+
+	if match, err = l.matcher().Match(p, ResolveVariables(p, p.GetResources(), r), r.Resource); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vars.capture("resource", match)
+
+	vr := *r
+	vr.Variables = vars
+	return &vr, nil
+}
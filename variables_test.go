@@ -0,0 +1,86 @@
+package ladon_test
+
+import (
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type variablesCondition struct {
+	seen Variables
+}
+
+func (c *variablesCondition) Fulfills(_ interface{}, r *Request) bool {
+	c.seen = r.Variables
+	return true
+}
+
+func (c *variablesCondition) GetName() string {
+	return "variablesCondition"
+}
+
+func TestCapturedVariables(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	require.NoError(t, warden.Manager.Create(&DefaultPolicy{
+		ID:        "own-profile",
+		Subjects:  []string{"<.*>"},
+		Actions:   []string{"<get|update>"},
+		Resources: []string{"resources:users:<.*>"},
+		Effect:    AllowAccess,
+		Conditions: Conditions{
+			"owner": &EqualsSubjectCondition{Variable: "resource.1"},
+		},
+	}))
+
+	for k, c := range []struct {
+		subjects []string
+		resource string
+		allowed  bool
+	}{
+		{subjects: []string{"peter"}, resource: "resources:users:peter", allowed: true},
+		{subjects: []string{"max", "peter"}, resource: "resources:users:peter", allowed: true},
+		{subjects: []string{"max"}, resource: "resources:users:peter", allowed: false},
+		{subjects: []string{"peter"}, resource: "resources:users:", allowed: false},
+	} {
+		r := &Request{Subjects: c.subjects, Action: "update", Resource: c.resource}
+		err := warden.IsAllowed(r)
+		assert.Equal(t, c.allowed, err == nil, "case %d: %v", k, err)
+		assert.Nil(t, r.Variables, "case %d", k)
+
+		d, err := warden.Explain(r)
+		require.NoError(t, err)
+		assert.Equal(t, c.allowed, d.Allowed, "case %d", k)
+	}
+}
+
+func TestCapturedVariablesNames(t *testing.T) {
+	cond := &variablesCondition{}
+	warden := &Ladon{Manager: NewMemoryManager()}
+	require.NoError(t, warden.Manager.Create(&DefaultPolicy{
+		ID:         "1",
+		Subjects:   []string{"users:<[a-z]+>"},
+		Actions:    []string{"<get|update>"},
+		Resources:  []string{"resources:<(?P<kind>[a-z]+)>:<[0-9]+>"},
+		Effect:     AllowAccess,
+		Conditions: Conditions{"variables": cond},
+	}))
+
+	require.NoError(t, warden.IsAllowed(&Request{
+		Subjects:  []string{"groups:admins", "users:peter"},
+		Action:    "get",
+		Resource:  "resources:articles:1234",
+		Variables: Variables{"tenant": "acme", "resource.1": "overridden"},
+	}))
+	assert.Equal(t, Variables{
+		"tenant":        "acme",
+		"action.1":      "get",
+		"subject.1":     "peter",
+		"resource.1":    "articles",
+		"resource.2":    "articles",
+		"resource.kind": "articles",
+		"resource.3":    "1234",
+	}, cond.seen)
+}
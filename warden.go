@@ -1,6 +1,9 @@
 package ladon
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 const (
 	KeyRawRequest = "http-request"
@@ -19,6 +22,17 @@ type Request struct {
 
 	// Context is the request's environmental context.
 	Context Context `json:"context"`
+
+	// Variables are the values captured by the patterns of the policy being evaluated. They are set by the
+	// warden for every policy and can be read by conditions.
+	Variables Variables `json:"-"`
+
+	// Time is the time the request is evaluated at, which time based conditions compare against. If it is zero,
+	// the warden sets it using its clock.
+	Time time.Time `json:"-"`
+
+	// body caches the decoded body of the raw http request for the duration of an authorization call.
+	body *requestBody
 }
 
 // Validate validates request is formatted correctly
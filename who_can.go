@@ -0,0 +1,115 @@
+package ladon
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Access lists the subject patterns which are allowed or denied to perform an action on a resource.
+type Access struct {
+	// Allow are the subject patterns which are allowed access.
+	Allow []Grantee `json:"allow"`
+
+	// Deny are the subject patterns which are denied access.
+	Deny []Grantee `json:"deny"`
+}
+
+// Grantee is a subject pattern together with the policies it was found in.
+type Grantee struct {
+	// Subject is the subject pattern.
+	Subject string `json:"subject"`
+
+	// Policies are the IDs of the policies containing the subject pattern.
+	Policies []string `json:"policies"`
+
+	// Conditional is true if every policy containing the subject pattern has conditions, i.e. access
+	// depends on the request's context.
+	Conditional bool `json:"conditional"`
+}
+
+// WhoCan returns the subject patterns which are allowed or denied to perform the action on the resource.
+// Policies whose patterns contain variables (see ResolveVariables) are included if the variables can match,
+// but their grantees are conditional, since access depends on the subject or context of the request.
+func (l *Ladon) WhoCan(action, resource string) (*Access, error) {
+	policies, err := l.Manager.FindPoliciesForResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].GetID() < policies[j].GetID()
+	})
+
+	a := &Access{Allow: []Grantee{}, Deny: []Grantee{}}
+	allow := map[string]int{}
+	deny := map[string]int{}
+	for _, p := range policies {
+		// The manager might return a superset, so make sure the policy actually applies.
+		am, av, err := l.matchesWildcard(p, p.GetActions(), action)
+		if err != nil {
+			return nil, err
+		} else if !am {
+			continue
+		}
+		rm, rv, err := l.matchesWildcard(p, p.GetResources(), resource)
+		if err != nil {
+			return nil, err
+		} else if !rm {
+			continue
+		}
+
+		grantees, index := &a.Allow, allow
+		if !p.AllowAccess() {
+			grantees, index = &a.Deny, deny
+		}
+
+		// Access also depends on the request if the policy only matched through a variable.
+		conditional := len(p.GetConditions()) > 0 || av || rv
+		for _, subject := range p.GetSubjects() {
+			conditional := conditional || HasVariables(subject)
+			if i, ok := index[subject]; ok {
+				g := &(*grantees)[i]
+				g.Policies = append(g.Policies, p.GetID())
+				g.Conditional = g.Conditional && conditional
+				continue
+			}
+
+			index[subject] = len(*grantees)
+			*grantees = append(*grantees, Grantee{
+				Subject:     subject,
+				Policies:    []string{p.GetID()},
+				Conditional: conditional,
+			})
+		}
+	}
+
+	return a, nil
+}
+
+// matchesWildcard returns true if one of the patterns matches the value. Variables match any value, because
+// they are only resolved when a request is evaluated, so the second result is true if the value only
+// matched because of a variable.
+func (l *Ladon) matchesWildcard(p Policy, patterns []string, value string) (bool, bool, error) {
+	var literal, variable []string
+	for _, pattern := range patterns {
+		if HasVariables(pattern) {
+			variable = append(variable, WildcardVariables(p, pattern))
+		} else {
+			literal = append(literal, pattern)
+		}
+	}
+
+	if ok, err := l.matcher().Matches(p, literal, value); err != nil {
+		return false, false, errors.WithStack(err)
+	} else if ok {
+		return true, false, nil
+	}
+	if len(variable) == 0 {
+		return false, false, nil
+	}
+	ok, err := l.matcher().Matches(p, variable, value)
+	if err != nil {
+		return false, false, errors.WithStack(err)
+	}
+	return ok, ok, nil
+}
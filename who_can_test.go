@@ -0,0 +1,69 @@
+package ladon_test
+
+import (
+	"testing"
+
+	. "github.com/d3sw/ladon"
+	. "github.com/d3sw/ladon/manager/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLadonWhoCan(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range pols {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	a, err := warden.WhoCan("delete", "myrn:some.domain.com:resource:123")
+	require.NoError(t, err)
+	assert.Equal(t, []Grantee{
+		{Subject: "max", Policies: []string{"1"}, Conditional: true},
+		{Subject: "peter", Policies: []string{"1"}, Conditional: true},
+		{Subject: "<zac|ken>", Policies: []string{"1"}, Conditional: true},
+	}, a.Allow)
+	assert.Empty(t, a.Deny)
+
+	a, err = warden.WhoCan("broadcast", "myrn:some.domain.com:resource:123")
+	require.NoError(t, err)
+	assert.Empty(t, a.Allow)
+	assert.Equal(t, []Grantee{{Subject: "max", Policies: []string{"3"}}}, a.Deny)
+
+	a, err = warden.WhoCan("update", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []Grantee{{Subject: "max", Policies: []string{"2"}}}, a.Allow)
+}
+
+func TestLadonWhoCanVariables(t *testing.T) {
+	warden := &Ladon{Manager: NewMemoryManager()}
+	for _, pol := range []Policy{
+		&DefaultPolicy{
+			ID:        "home",
+			Subjects:  []string{"<.*>"},
+			Actions:   []string{"get"},
+			Resources: []string{"files:home:${subject}"},
+			Effect:    AllowAccess,
+		},
+		&DefaultPolicy{
+			ID:        "owner",
+			Subjects:  []string{"${context.owner}"},
+			Actions:   []string{"delete"},
+			Resources: []string{"files:home:<.*>"},
+			Effect:    AllowAccess,
+		},
+	} {
+		require.Nil(t, warden.Manager.Create(pol))
+	}
+
+	a, err := warden.WhoCan("get", "files:home:ken")
+	require.NoError(t, err)
+	assert.Equal(t, []Grantee{{Subject: "<.*>", Policies: []string{"home"}, Conditional: true}}, a.Allow)
+
+	a, err = warden.WhoCan("delete", "files:home:ken")
+	require.NoError(t, err)
+	assert.Equal(t, []Grantee{{Subject: "${context.owner}", Policies: []string{"owner"}, Conditional: true}}, a.Allow)
+
+	a, err = warden.WhoCan("get", "files:shared:ken")
+	require.NoError(t, err)
+	assert.Empty(t, a.Allow)
+}